@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+
+	"microservice/internal/config"
+)
+
+// runConfig implements the `config` subcommand. The `dump` action writes the
+// configuration the service would start with to stdout, using the format of
+// the configuration file. As the configuration is validated while it is
+// loaded, an invalid configuration is reported before the action runs
+func runConfig(arguments []string) int {
+	if len(arguments) != 1 || arguments[0] != "dump" {
+		fmt.Fprintln(os.Stderr, "usage: service config dump")
+		return 2
+	}
+
+	dump, err := config.Current.Dump()
+	if err != nil {
+		log.Error().Str("command", "config").Err(err).Msg("unable to dump configuration")
+		return 1
+	}
+	_, _ = os.Stdout.Write(dump)
+	return 0
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+
+	"microservice/internal/db"
+	"microservice/internal/ingest"
+	"microservice/structs"
+)
+
+// runImport implements the `import` subcommand which imports the usage
+// records contained in a CSV or XLSX file without starting the http server.
+// The report of the import is written to stdout. The returned exit code is
+// non-zero if the import failed or the file contained invalid rows
+func runImport(arguments []string) int {
+	l := log.With().Str("command", "import").Logger()
+
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: service import [options] <file>")
+		flags.PrintDefaults()
+	}
+	dryRun := flags.Bool("dryRun", false, "only validate the file without storing the usage records")
+	sheet := flags.String("sheet", "", "sheet of a XLSX file containing the usage records (default: first sheet)")
+	delimiter := flags.String("delimiter", ",", "delimiter used in a CSV file")
+	decimalSeparator := flags.String("decimalSeparator", ".", "decimal separator used for the amounts")
+	timeFormat := flags.String("timeFormat", time.RFC3339, "layout of the timestamps as used by the go time package")
+	var columns structs.ColumnMapping
+	flags.StringVar(&columns.Time, "timeColumn", "time", "name of the column containing the time")
+	flags.StringVar(&columns.Amount, "amountColumn", "amount", "name of the column containing the amount")
+	flags.StringVar(&columns.UsageType, "usageTypeColumn", "usageType", "name of the column containing the usage type")
+	flags.StringVar(&columns.ConsumerID, "consumerColumn", "consumerID", "name of the column containing the consumer id")
+	flags.StringVar(&columns.ARS, "arsColumn", "ars", "name of the column containing the ARS")
+
+	if err := flags.Parse(arguments); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	if utf8.RuneCountInString(*delimiter) != 1 || (*decimalSeparator != "." && *decimalSeparator != ",") {
+		l.Error().Msg("the delimiter needs to be a single character and the decimal separator needs to be '.' or ','")
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	format, err := ingest.FileFormat(filename)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to import file")
+		return 2
+	}
+
+	delimiterRune, _ := utf8.DecodeRuneInString(*delimiter)
+	options := ingest.Options{
+		Format:           format,
+		Delimiter:        delimiterRune,
+		DecimalSeparator: *decimalSeparator,
+		Sheet:            *sheet,
+		TimeFormat:       *timeFormat,
+		Columns:          columns,
+		DryRun:           *dryRun,
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to open file")
+		return 1
+	}
+	defer file.Close()
+
+	table, err := ingest.ReadTable(file, options)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to read file")
+		return 1
+	}
+
+	db.Connect()
+
+	report, err := ingest.Import(context.Background(), table, options)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to import file")
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(report)
+
+	if len(report.Issues) > 0 {
+		return 1
+	}
+	return 0
+}
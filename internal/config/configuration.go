@@ -0,0 +1,326 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the configuration of the service. The configuration
+// starts with the defaults of the build, which are overwritten by the values
+// of the optional configuration file and the environment variables in this
+// order
+
+// FileVariable is the environment variable containing the path to the
+// optional configuration file. The file uses the yaml keys of the
+// Configuration
+const FileVariable = "CONFIG_FILE"
+
+// Current contains the configuration loaded at the app startup
+var Current Configuration
+
+func init() {
+	var err error
+	Current, err = Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid configuration")
+	}
+}
+
+// Configuration contains every setting of the service. The env tag contains
+// the environment variable overwriting the setting. Lists are supplied as
+// comma separated values and durations use the format of time.ParseDuration
+type Configuration struct {
+	LogLevel string `yaml:"logLevel" env:"LOG_LEVEL" validate:"loglevel"`
+
+	HTTP       HTTPConfiguration       `yaml:"http"`
+	CORS       CORSConfiguration       `yaml:"cors"`
+	Auth       AuthConfiguration       `yaml:"auth"`
+	Pagination PaginationConfiguration `yaml:"pagination"`
+	Database   DatabaseConfiguration   `yaml:"database"`
+	Cache      CacheConfiguration      `yaml:"cache"`
+	Ingest     IngestConfiguration     `yaml:"ingest"`
+}
+
+// HTTPConfiguration contains the settings of the http servers
+type HTTPConfiguration struct {
+	ListenAddress        string        `yaml:"listenAddress" env:"LISTEN_ADDRESS" validate:"hostname_port"`
+	MetricsListenAddress string        `yaml:"metricsListenAddress" env:"METRICS_LISTEN_ADDRESS" validate:"hostname_port"`
+	TrustedProxies       []string      `yaml:"trustedProxies" env:"TRUSTED_PROXIES" validate:"dive,cidr|ip"`
+	ReadHeaderTimeout    time.Duration `yaml:"readHeaderTimeout" env:"READ_HEADER_TIMEOUT" validate:"gt=0"`
+	ReadTimeout          time.Duration `yaml:"readTimeout" env:"READ_TIMEOUT" validate:"gte=0"`
+	WriteTimeout         time.Duration `yaml:"writeTimeout" env:"WRITE_TIMEOUT" validate:"gte=0"`
+	IdleTimeout          time.Duration `yaml:"idleTimeout" env:"IDLE_TIMEOUT" validate:"gte=0"`
+	ShutdownTimeout      time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT" validate:"gt=0"`
+}
+
+// CORSConfiguration contains the settings for cross-origin requests. If no
+// origins are allowed, cross-origin requests are not answered
+type CORSConfiguration struct {
+	AllowedOrigins []string      `yaml:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS" validate:"dive,eq=*|http_url"`
+	AllowedHeaders []string      `yaml:"allowedHeaders" env:"CORS_ALLOWED_HEADERS"`
+	MaxAge         time.Duration `yaml:"maxAge" env:"CORS_MAX_AGE" validate:"gte=0"`
+}
+
+// AuthConfiguration contains the settings for the authentication of the
+// requests. The local development build does not authenticate requests
+type AuthConfiguration struct {
+	OIDCAuthority string `yaml:"oidcAuthority" env:"OIDC_AUTHORITY" validate:"http_url"`
+}
+
+// The documented page limits are generated from the Defaults
+//go:generate go run ./openapi-limits ../../openapi.yaml
+
+// PaginationConfiguration contains the page limits used by every route and
+// the limits of single routes overriding them. The routes are identified by
+// their template, e.g., /municipal/*ars, and can only be configured in the
+// configuration file
+type PaginationConfiguration struct {
+	PageLimits `yaml:",inline"`
+	Routes     map[string]PageLimits `yaml:"routes" validate:"dive"`
+}
+
+// PageLimits contains the page size used if a request does not supply one and
+// the largest page size a request may supply
+type PageLimits struct {
+	DefaultPageSize int `yaml:"defaultPageSize" env:"DEFAULT_PAGE_SIZE" validate:"min=1,ltefield=MaxPageSize"`
+	MaxPageSize     int `yaml:"maxPageSize" env:"MAX_PAGE_SIZE" validate:"min=1"`
+}
+
+// Limits returns the page limits of the route. If the route has no limits of
+// its own, the limits of every route are returned
+func (c PaginationConfiguration) Limits(route string) PageLimits {
+	if limits, found := c.Routes[route]; found {
+		return limits
+	}
+	return c.PageLimits
+}
+
+// DatabaseConfiguration contains the settings of the connection pool. The
+// connection itself is configured using the PG* environment variables. A
+// value of zero uses the default of the pgxpool package.
+// The pending migrations need to be applied using the migrate command, unless
+// MigrateOnStart is set, which applies them at the startup
+type DatabaseConfiguration struct {
+	MigrateOnStart bool          `yaml:"migrateOnStart" env:"DB_MIGRATE_ON_START"`
+	MaxConnections int32         `yaml:"maxConnections" env:"DB_MAX_CONNECTIONS" validate:"omitempty,gtefield=MinConnections"`
+	MinConnections int32         `yaml:"minConnections" env:"DB_MIN_CONNECTIONS" validate:"gte=0"`
+	ConnectTimeout time.Duration `yaml:"connectTimeout" env:"DB_CONNECT_TIMEOUT" validate:"gte=0"`
+}
+
+// CacheConfiguration contains the settings of the query result cache. The
+// size limits the number of cached results and MaxRows the number of rows
+// they contain in total. Results with more rows are not cached. A size or
+// MaxRows of zero disables the cache
+type CacheConfiguration struct {
+	Size    int           `yaml:"size" env:"CACHE_SIZE" validate:"gte=0"`
+	MaxRows int           `yaml:"maxRows" env:"CACHE_MAX_ROWS" validate:"gte=0"`
+	TTL     time.Duration `yaml:"ttl" env:"CACHE_TTL" validate:"gt=0"`
+}
+
+// IngestConfiguration contains the limits of the requests storing usage
+// records. The body size is limited in bytes
+type IngestConfiguration struct {
+	MaxBodySize     int64 `yaml:"maxBodySize" env:"INGEST_MAX_BODY_SIZE" validate:"min=1"`
+	MaxBatchRecords int   `yaml:"maxBatchRecords" env:"INGEST_MAX_BATCH_RECORDS" validate:"min=1"`
+}
+
+// Defaults returns the configuration used if neither a configuration file
+// nor environment variables are supplied
+func Defaults() Configuration {
+	return Configuration{
+		LogLevel: zerolog.InfoLevel.String(),
+		HTTP: HTTPConfiguration{
+			ListenAddress:        defaultListenAddress,
+			MetricsListenAddress: defaultMetricsListenAddress,
+			TrustedProxies:       defaultTrustedProxies,
+			ReadHeaderTimeout:    10 * time.Second,
+			IdleTimeout:          2 * time.Minute,
+			ShutdownTimeout:      10 * time.Second,
+		},
+		CORS: CORSConfiguration{
+			AllowedHeaders: []string{"Authorization", "Content-Type", "If-None-Match", "If-Modified-Since"},
+			MaxAge:         12 * time.Hour,
+		},
+		Auth: AuthConfiguration{
+			OIDCAuthority: "http://backend/api/auth/",
+		},
+		Pagination: PaginationConfiguration{
+			PageLimits: PageLimits{
+				DefaultPageSize: 10000,
+				MaxPageSize:     100000,
+			},
+		},
+		Cache: CacheConfiguration{
+			Size:    1024,
+			MaxRows: 500000,
+			TTL:     5 * time.Minute,
+		},
+		Ingest: IngestConfiguration{
+			MaxBodySize:     32 << 20,
+			MaxBatchRecords: 50000,
+		},
+	}
+}
+
+// Load reads the configuration file set in the FileVariable and the
+// environment variables on top of the Defaults and validates the resulting
+// configuration
+func Load() (Configuration, error) {
+	configuration := Defaults()
+
+	if path, isSet := os.LookupEnv(FileVariable); isSet {
+		file, err := os.Open(path)
+		if err != nil {
+			return Configuration{}, fmt.Errorf("unable to read configuration file: %w", err)
+		}
+		defer file.Close()
+
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&configuration); err != nil && !errors.Is(err, io.EOF) {
+			return Configuration{}, fmt.Errorf("unable to parse configuration file: %w", err)
+		}
+	}
+
+	if err := readEnvironment(reflect.ValueOf(&configuration).Elem()); err != nil {
+		return Configuration{}, err
+	}
+
+	configuration.Pagination.fillRouteLimits()
+
+	if err := configuration.Validate(); err != nil {
+		return Configuration{}, err
+	}
+	return configuration, nil
+}
+
+// fillRouteLimits sets the limits which have not been set for a route to the
+// limits of every route. The default page size is capped by the maximum page
+// size of the route
+func (c *PaginationConfiguration) fillRouteLimits() {
+	for route, limits := range c.Routes {
+		if limits.MaxPageSize == 0 {
+			limits.MaxPageSize = c.MaxPageSize
+		}
+		if limits.DefaultPageSize == 0 {
+			limits.DefaultPageSize = min(c.DefaultPageSize, limits.MaxPageSize)
+		}
+		c.Routes[route] = limits
+	}
+}
+
+// Validate checks every setting of the configuration and returns an error
+// naming the invalid settings
+func (c Configuration) Validate() error {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if variable := field.Tag.Get("env"); variable != "" {
+			return variable
+		}
+		return field.Name
+	})
+	_ = validate.RegisterValidation("loglevel", func(field validator.FieldLevel) bool {
+		_, err := zerolog.ParseLevel(field.Field().String())
+		return err == nil
+	})
+
+	err := validate.Struct(c)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	var invalidSettings []string
+	for _, fieldError := range validationErrors {
+		invalidSettings = append(invalidSettings, fmt.Sprintf("%s failed the '%s' check", fieldError.Field(), fieldError.Tag()))
+	}
+	return fmt.Errorf("invalid settings: %s", strings.Join(invalidSettings, ", "))
+}
+
+// Dump outputs the configuration in the format of the configuration file
+func (c Configuration) Dump() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// durationType is used to detect time.Duration fields as they are integers
+// otherwise
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// readEnvironment overwrites the fields of the struct with the values of the
+// environment variables named in their env tags. Nested structs are read
+// recursively
+func readEnvironment(value reflect.Value) error {
+	for i := range value.NumField() {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			if err := readEnvironment(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		variable := structField.Tag.Get("env")
+		rawValue, isSet := os.LookupEnv(variable)
+		if variable == "" || !isSet {
+			continue
+		}
+
+		if err := setField(field, rawValue); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", variable, err)
+		}
+	}
+	return nil
+}
+
+// setField parses the raw value into the type of the field
+func setField(field reflect.Value, rawValue string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(rawValue)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(rawValue)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		number, err := strconv.ParseInt(rawValue, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(number)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(rawValue)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolean)
+	case reflect.Slice:
+		var values []string
+		for _, value := range strings.Split(rawValue, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		check func(t *testing.T, configuration Configuration)
+	}{
+		{
+			name: "Defaults",
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, Defaults(), configuration)
+			},
+		},
+		{
+			name: "File_Overrides_Defaults",
+			file: "logLevel: warn\ncache:\n  size: 10\n",
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, "warn", configuration.LogLevel)
+				assert.Equal(t, 10, configuration.Cache.Size)
+				assert.Equal(t, Defaults().Cache.TTL, configuration.Cache.TTL)
+			},
+		},
+		{
+			name: "Environment_Overrides_File",
+			file: "logLevel: warn\ncache:\n  size: 10\n",
+			env:  map[string]string{"CACHE_SIZE": "20"},
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, "warn", configuration.LogLevel)
+				assert.Equal(t, 20, configuration.Cache.Size)
+			},
+		},
+		{
+			name: "Empty_File",
+			file: "",
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, Defaults(), configuration)
+			},
+		},
+		{
+			name: "Comma_Separated_List",
+			env:  map[string]string{"TRUSTED_PROXIES": " 10.0.0.0/8, ,192.168.1.1 "},
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, configuration.HTTP.TrustedProxies)
+			},
+		},
+		{
+			name: "Empty_List",
+			file: "http:\n  trustedProxies: [10.0.0.0/8]\n",
+			env:  map[string]string{"TRUSTED_PROXIES": ""},
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Empty(t, configuration.HTTP.TrustedProxies)
+			},
+		},
+		{
+			name: "Durations",
+			file: "cache:\n  ttl: 2m\n",
+			env:  map[string]string{"READ_TIMEOUT": "1m30s"},
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, 2*time.Minute, configuration.Cache.TTL)
+				assert.Equal(t, 90*time.Second, configuration.HTTP.ReadTimeout)
+			},
+		},
+		{
+			name: "Booleans",
+			env:  map[string]string{"DB_MIGRATE_ON_START": "true"},
+			check: func(t *testing.T, configuration Configuration) {
+				assert.True(t, configuration.Database.MigrateOnStart)
+			},
+		},
+		{
+			name: "Route_Limits",
+			file: "pagination:\n  defaultPageSize: 100\n  maxPageSize: 500\n  routes:\n    /municipal/*ars:\n      maxPageSize: 50\n    /consumers:\n      defaultPageSize: 5\n",
+			check: func(t *testing.T, configuration Configuration) {
+				assert.Equal(t, PageLimits{DefaultPageSize: 50, MaxPageSize: 50}, configuration.Pagination.Limits("/municipal/*ars"))
+				assert.Equal(t, PageLimits{DefaultPageSize: 5, MaxPageSize: 500}, configuration.Pagination.Limits("/consumers"))
+				assert.Equal(t, configuration.Pagination.PageLimits, configuration.Pagination.Limits("/types"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setConfiguration(t, test.file, test.env)
+
+			configuration, err := Load()
+			if !assert.NoError(t, err) {
+				return
+			}
+			test.check(t, configuration)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		error string
+	}{
+		{
+			name:  "Unknown_Setting",
+			file:  "cache:\n  entries: 10\n",
+			error: "unable to parse configuration file: yaml: unmarshal errors:\n  line 2: field entries not found in type config.CacheConfiguration",
+		},
+		{
+			name:  "Malformed_File",
+			file:  "cache: [",
+			error: "unable to parse configuration file",
+		},
+		{
+			name:  "Missing_File",
+			env:   map[string]string{FileVariable: filepath.Join(os.TempDir(), "missing", "config.yaml")},
+			error: "unable to read configuration file",
+		},
+		{
+			name:  "Malformed_Number",
+			env:   map[string]string{"CACHE_SIZE": "many"},
+			error: "invalid value for CACHE_SIZE",
+		},
+		{
+			name:  "Malformed_Duration",
+			env:   map[string]string{"CACHE_TTL": "5"},
+			error: "invalid value for CACHE_TTL",
+		},
+		{
+			name:  "Malformed_Boolean",
+			env:   map[string]string{"DB_MIGRATE_ON_START": "sometimes"},
+			error: "invalid value for DB_MIGRATE_ON_START",
+		},
+		{
+			name:  "Invalid_Log_Level",
+			env:   map[string]string{"LOG_LEVEL": "verbose"},
+			error: "invalid settings: LOG_LEVEL failed the 'loglevel' check",
+		},
+		{
+			name:  "Invalid_List_Entry",
+			env:   map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy"},
+			error: "invalid settings: TRUSTED_PROXIES[1] failed the 'cidr|ip' check",
+		},
+		{
+			name:  "Default_Exceeds_Maximum",
+			env:   map[string]string{"DEFAULT_PAGE_SIZE": "200", "MAX_PAGE_SIZE": "100"},
+			error: "invalid settings: DEFAULT_PAGE_SIZE failed the 'ltefield' check",
+		},
+		{
+			name:  "Several_Invalid_Settings",
+			env:   map[string]string{"CACHE_SIZE": "-1", "CACHE_TTL": "0s"},
+			error: "invalid settings: CACHE_SIZE failed the 'gte' check, CACHE_TTL failed the 'gt' check",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setConfiguration(t, test.file, test.env)
+
+			_, err := Load()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.error)
+			}
+		})
+	}
+}
+
+// setConfiguration writes the configuration file, unless the environment
+// names another one, and sets the environment variables for the test
+func setConfiguration(t *testing.T, file string, env map[string]string) {
+	t.Helper()
+
+	if _, isSet := env[FileVariable]; !isSet {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(FileVariable, path)
+	}
+
+	for variable, value := range env {
+		t.Setenv(variable, value)
+	}
+}
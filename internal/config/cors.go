@@ -0,0 +1,25 @@
+package config
+
+import (
+	"slices"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// exposedHeaders contains the response headers which may be read by the
+// scripts of the allowed origins
+var exposedHeaders = []string{"ETag", "Last-Modified", "Link", "X-Next-Cursor", "X-Total-Count", "Content-Disposition"}
+
+// corsMiddleware answers the preflight requests and sets the CORS headers of
+// the responses to requests from the origins allowed in the CORSConfiguration
+func corsMiddleware(configuration CORSConfiguration) gin.HandlerFunc {
+	return cors.New(cors.Config{
+		AllowAllOrigins: slices.Contains(configuration.AllowedOrigins, "*"),
+		AllowOrigins:    slices.DeleteFunc(slices.Clone(configuration.AllowedOrigins), func(origin string) bool { return origin == "*" }),
+		AllowMethods:    []string{"GET", "POST", "HEAD", "OPTIONS"},
+		AllowHeaders:    configuration.AllowedHeaders,
+		ExposeHeaders:   exposedHeaders,
+		MaxAge:          configuration.MaxAge,
+	})
+}
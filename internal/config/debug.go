@@ -14,6 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 
+	"microservice/internal/metrics"
+	"microservice/internal/tracing"
+
 	"github.com/gin-contrib/logger"
 	"github.com/gin-contrib/requestid"
 
@@ -22,14 +25,28 @@ import (
 	"github.com/wisdom-oss/common-go/v3/middleware/gin/recoverer"
 )
 
-const ListenAddress = "127.0.0.1:8000"
+// The defaults of the listen addresses used if they are not configured.
+// The metrics use a separate listener to keep them out of the public api
+const (
+	defaultListenAddress        = "127.0.0.1:8000"
+	defaultMetricsListenAddress = "127.0.0.1:9000"
+)
+
+// defaultTrustedProxies is empty as no proxy is used during the local
+// development
+var defaultTrustedProxies []string
 
 // Middlewares configures and outputs the middlewares used in the configuration.
 // The contained middlewares are the following:
+//   - metrics.Middleware
 //   - gin.Logger
+//   - CORS, if origins are allowed
+//   - tracing.Middlewares
 func Middlewares() []gin.HandlerFunc {
 	var middlewares []gin.HandlerFunc
 
+	middlewares = append(middlewares, metrics.Middleware)
+
 	middlewares = append(middlewares,
 		logger.SetLogger(
 			logger.WithDefaultLevel(zerolog.DebugLevel),
@@ -37,6 +54,10 @@ func Middlewares() []gin.HandlerFunc {
 		))
 
 	middlewares = append(middlewares, requestid.New())
+	if len(Current.CORS.AllowedOrigins) > 0 {
+		middlewares = append(middlewares, corsMiddleware(Current.CORS))
+	}
+	middlewares = append(middlewares, tracing.Middlewares()...)
 	middlewares = append(middlewares, errorHandler.Handler)
 	middlewares = append(middlewares, gin.CustomRecovery(recoverer.RecoveryHandler))
 
@@ -51,6 +72,10 @@ func Middlewares() []gin.HandlerFunc {
 func PrepareRouter() *gin.Engine {
 	router := gin.New()
 	router.HandleMethodNotAllowed = true
+	_ = router.SetTrustedProxies(Current.HTTP.TrustedProxies)
+	// the request context contains the span of the request which needs to be
+	// the parent of the spans of the queries executed with the gin context
+	router.ContextWithFallback = true
 	router.Use(Middlewares()...)
 
 	router.NoMethod(func(c *gin.Context) {
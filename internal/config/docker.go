@@ -10,7 +10,6 @@ package config
 
 import (
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -18,11 +17,23 @@ import (
 	"github.com/wisdom-oss/common-go/v3/middleware/gin/jwt"
 	"github.com/wisdom-oss/common-go/v3/middleware/gin/recoverer"
 
+	"microservice/internal/metrics"
+	"microservice/internal/tracing"
+
 	"github.com/gin-contrib/logger"
 	"github.com/gin-contrib/requestid"
 )
 
-const ListenAddress = "0.0.0.0:8000"
+// The defaults of the listen addresses used if they are not configured.
+// The metrics use a separate listener to keep them out of the public api
+const (
+	defaultListenAddress        = "0.0.0.0:8000"
+	defaultMetricsListenAddress = "0.0.0.0:9000"
+)
+
+// defaultTrustedProxies contains the private network ranges used by the
+// container networks the service is deployed in
+var defaultTrustedProxies = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
 
 func init() {
 	// set gin to the production mode (aka release mode)
@@ -31,10 +42,15 @@ func init() {
 
 // Middlewares configures and outputs the middlewares used in the configuration.
 // The contained middlewares are the following:
+//   - metrics.Middleware
 //   - gin.Logger
+//   - CORS, if origins are allowed
+//   - tracing.Middlewares
 func Middlewares() []gin.HandlerFunc {
 	var middlewares []gin.HandlerFunc
 
+	middlewares = append(middlewares, metrics.Middleware)
+
 	middlewares = append(middlewares,
 		logger.SetLogger(
 			logger.WithDefaultLevel(zerolog.DebugLevel),
@@ -42,17 +58,15 @@ func Middlewares() []gin.HandlerFunc {
 		))
 
 	middlewares = append(middlewares, requestid.New())
+	if len(Current.CORS.AllowedOrigins) > 0 {
+		middlewares = append(middlewares, corsMiddleware(Current.CORS))
+	}
+	middlewares = append(middlewares, tracing.Middlewares()...)
 	middlewares = append(middlewares, errorHandler.Handler)
 	middlewares = append(middlewares, gin.CustomRecovery(recoverer.RecoveryHandler))
 
-	// read the OIDC authority from the environment
-	oidcAuthority, isSet := os.LookupEnv("OIDC_AUTHORITY")
-	if !isSet {
-		oidcAuthority = "http://backend/api/auth/"
-	}
-
 	validator := jwt.Validator{}
-	err := validator.Discover(oidcAuthority)
+	err := validator.Discover(Current.Auth.OIDCAuthority)
 	if err != nil {
 		panic(err)
 	}
@@ -65,8 +79,11 @@ func Middlewares() []gin.HandlerFunc {
 func PrepareRouter() *gin.Engine {
 	router := gin.New()
 	router.HandleMethodNotAllowed = true
+	// the request context contains the span of the request which needs to be
+	// the parent of the spans of the queries executed with the gin context
+	router.ContextWithFallback = true
 	router.ForwardedByClientIP = true
-	_ = router.SetTrustedProxies([]string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"})
+	_ = router.SetTrustedProxies(Current.HTTP.TrustedProxies)
 	router.Use(Middlewares()...)
 
 	router.NoMethod(func(c *gin.Context) {
@@ -0,0 +1,85 @@
+// openapi-limits writes the page limits of the default configuration into the
+// parameters of the OpenAPI document to prevent the documented limits from
+// drifting apart from the limits used by the service. It replaces everything
+// between the markers in the supplied document.
+//
+// usage: go run ./internal/config/openapi-limits <openapi document>
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+
+	"microservice/internal/config"
+)
+
+var (
+	beginMarker = []byte("    # BEGIN generated page limits\n")
+	endMarker   = []byte("    # END generated page limits\n")
+)
+
+var parameters = template.Must(template.New("parameters").Parse(`    # do not edit, run go generate ./internal/config after changing the limits
+    Size:
+      in: query
+      name: size
+      description: |
+        Number of items per page. The limits may be changed per deployment and
+        route, the limits documented here are the defaults of the service
+      schema:
+        type: integer
+        default: {{ .DefaultPageSize }}
+        minimum: 1
+        maximum: {{ .MaxPageSize }}
+
+    PageSize:
+      in: query
+      name: pageSize
+      deprecated: true
+      description: |
+        Previous name of the ` + "`size`" + ` parameter. It may only be combined with
+        ` + "`size`" + ` if both contain the same page size
+      schema:
+        type: integer
+        default: {{ .DefaultPageSize }}
+        minimum: 1
+        maximum: {{ .MaxPageSize }}
+
+`))
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: openapi-limits <openapi document>")
+		os.Exit(2)
+	}
+
+	if err := generate(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generate replaces the parameters between the markers of the document with
+// the parameters containing the page limits of the default configuration
+func generate(path string) error {
+	document, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	begin := bytes.Index(document, beginMarker)
+	end := bytes.Index(document, endMarker)
+	if begin < 0 || end < begin {
+		return fmt.Errorf("%s does not contain the markers of the page limits", path)
+	}
+
+	var generated bytes.Buffer
+	generated.Write(document[:begin+len(beginMarker)])
+	if err := parameters.Execute(&generated, config.Defaults().Pagination.PageLimits); err != nil {
+		return err
+	}
+	generated.Write(document[end:])
+
+	return os.WriteFile(path, generated.Bytes(), 0o644)
+}
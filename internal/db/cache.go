@@ -0,0 +1,336 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// This file contains the cache for the results of the usage queries. The
+// cache is invalidated by the database as soon as the usages change
+
+// UsageChannel is the channel the database notifies after the usages have
+// been changed
+const UsageChannel = "water_usage_changed"
+
+// listenRetryInterval is the time waited before reconnecting the listener
+// after the connection has been lost
+const listenRetryInterval = 5 * time.Second
+
+// Cache contains the results of the usage queries. It is disabled until it
+// has been configured by Connect
+var Cache = NewResultCache(0, 0, 0)
+
+// CacheStats contains the counters of a ResultCache
+type CacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Evictions     uint64
+	Invalidations uint64
+	Entries       int
+	Rows          int
+}
+
+// ResultCache is a bounded cache for query results. The cache is bounded by
+// the number of results and the number of rows they contain. If the cache is
+// full, the least recently used results are evicted. Results containing more
+// rows than the cache may hold are not cached. Results are only cached while
+// the cache is enabled, which happens once the cache listens for changed
+// usages. Concurrent misses for the same key only execute the query once
+type ResultCache struct {
+	size    int
+	maxRows int
+	ttl     time.Duration
+
+	mutex      sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	rows       int
+	generation uint64
+	enabled    bool
+
+	loads singleflight.Group
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	evictions     atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	rows    int
+	expires time.Time
+}
+
+// NewResultCache creates a cache holding up to size results with up to
+// maxRows rows in total for the ttl. A size or maxRows of zero disables the
+// cache
+func NewResultCache(size int, maxRows int, ttl time.Duration) *ResultCache {
+	return &ResultCache{
+		size:    size,
+		maxRows: maxRows,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Stats returns the current counters of the cache
+func (c *ResultCache) Stats() CacheStats {
+	c.mutex.Lock()
+	entries, rows := c.order.Len(), c.rows
+	c.mutex.Unlock()
+
+	return CacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Evictions:     c.evictions.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+		Rows:          rows,
+	}
+}
+
+// Purge removes every result from the cache. Queries which are executed while
+// the cache is purged do not store their results as they may be outdated
+func (c *ResultCache) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.rows = 0
+	c.invalidations.Add(1)
+}
+
+// setEnabled purges the cache and sets if results are stored
+func (c *ResultCache) setEnabled(enabled bool) {
+	c.Purge()
+	c.mutex.Lock()
+	c.enabled = enabled
+	c.mutex.Unlock()
+}
+
+// get returns the cached result for the key if it has not expired yet
+func (c *ResultCache) get(key string) (any, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// add stores the result containing the rows for the key unless the cache has
+// been purged since the generation has been read or the result is larger than
+// the cache
+func (c *ResultCache) add(key string, value any, rows int, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.enabled || c.generation != generation || rows > c.maxRows {
+		return
+	}
+
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+	entry := &cacheEntry{key: key, value: value, rows: rows, expires: time.Now().Add(c.ttl)}
+	c.entries[key] = c.order.PushFront(entry)
+	c.rows += rows
+
+	for c.order.Len() > c.size || c.rows > c.maxRows {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// remove deletes the entry of the element from the cache. The mutex needs to
+// be held by the caller
+func (c *ResultCache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.rows -= entry.rows
+}
+
+// load returns the cached result for the key or executes the loader and
+// caches its result together with the number of rows it returned. The loader
+// is executed independently of the cancellation of the context as its result
+// may be shared with other requests
+func (c *ResultCache) load(ctx context.Context, key string, loader func(ctx context.Context) (any, int, error)) (any, error) {
+	if c.size <= 0 || c.maxRows <= 0 {
+		value, _, err := loader(ctx)
+		return value, err
+	}
+
+	if value, found := c.get(key); found {
+		c.hits.Add(1)
+		return value, nil
+	}
+	c.misses.Add(1)
+
+	value, err, _ := c.loads.Do(key, func() (any, error) {
+		c.mutex.Lock()
+		generation := c.generation
+		c.mutex.Unlock()
+
+		value, rows, err := loader(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		c.add(key, value, rows, generation)
+		return value, nil
+	})
+	return value, err
+}
+
+// errMissingDataVersion is returned by cacheKey if the context does not
+// contain the data version of the usages
+var errMissingDataVersion = errors.New("missing data version")
+
+// dataVersionKey is the context key of the data version set by
+// WithDataVersion
+type dataVersionKey struct{}
+
+// WithDataVersion returns a context containing the data version of the
+// queried usages. The data version is part of the cache key, therefore a
+// result loaded for an older data version is not returned even if the cache
+// has not been purged yet. Queries without a data version are not cached
+func WithDataVersion(ctx context.Context, version pgtype.Timestamptz) context.Context {
+	return context.WithValue(ctx, dataVersionKey{}, version)
+}
+
+// cacheKey derives the key of a query result from the data version contained
+// in the context, the destination type, the query and its arguments. The page
+// settings are part of the query and its arguments and therefore also part of
+// the key
+func cacheKey(ctx context.Context, dst any, query string, args []any) (string, error) {
+	version, isSet := ctx.Value(dataVersionKey{}).(pgtype.Timestamptz)
+	if !isSet {
+		return "", errMissingDataVersion
+	}
+
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if version.Valid {
+		hash.Write([]byte(version.Time.UTC().Format(time.RFC3339Nano)))
+	}
+	fmt.Fprintf(hash, "\x00%T\x00%s\x00", dst, query)
+	hash.Write(encodedArgs)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CachedSelect is like pgxscan.Select but returns the cached rows if the
+// query has already been executed with the same arguments for the data
+// version of the context. The returned rows are shared between the callers
+// and must not be modified
+func CachedSelect[T any](ctx context.Context, dst *[]T, query string, args ...any) error {
+	key, err := cacheKey(ctx, dst, query, args)
+	if err != nil {
+		return pgxscan.Select(ctx, Pool, dst, query, args...)
+	}
+
+	value, err := Cache.load(ctx, key, func(ctx context.Context) (any, int, error) {
+		var rows []T
+		err := pgxscan.Select(ctx, Pool, &rows, query, args...)
+		return rows, len(rows), err
+	})
+	if err != nil {
+		return err
+	}
+	*dst = value.([]T)
+	return nil
+}
+
+// CachedGet is like pgxscan.Get but returns the cached row if the query has
+// already been executed with the same arguments for the data version of the
+// context
+func CachedGet[T any](ctx context.Context, dst *T, query string, args ...any) error {
+	key, err := cacheKey(ctx, dst, query, args)
+	if err != nil {
+		return pgxscan.Get(ctx, Pool, dst, query, args...)
+	}
+
+	value, err := Cache.load(ctx, key, func(ctx context.Context) (any, int, error) {
+		var row T
+		err := pgxscan.Get(ctx, Pool, &row, query, args...)
+		return row, 1, err
+	})
+	if err != nil {
+		return err
+	}
+	*dst = value.(T)
+	return nil
+}
+
+// ListenForUsageChanges purges the cache every time the database notifies
+// the UsageChannel. The cache only stores results while it listens for the
+// notifications, as changes could be missed otherwise. If the connection is
+// lost, the listener reconnects until the context is canceled
+func ListenForUsageChanges(ctx context.Context) {
+	l := log.With().Str("package", "internal/db").Logger()
+	for {
+		err := listenForUsageChanges(ctx)
+		Cache.setEnabled(false)
+		if ctx.Err() != nil {
+			return
+		}
+		l.Warn().Err(err).Msg("lost connection while listening for usage changes")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryInterval):
+		}
+	}
+}
+
+// listenForUsageChanges listens on a dedicated connection for notifications
+// on the UsageChannel and purges the cache for each of them
+func listenForUsageChanges(ctx context.Context) error {
+	conn, err := Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	listener := conn.Hijack()
+	defer listener.Close(context.Background())
+
+	if _, err := listener.Exec(ctx, "LISTEN "+UsageChannel); err != nil {
+		return err
+	}
+	Cache.setEnabled(true)
+
+	for {
+		notification, err := listener.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		Cache.Purge()
+		log.Debug().Str("operation", notification.Payload).Msg("usages changed, purged cache")
+	}
+}
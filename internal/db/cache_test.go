@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+// enabledCache creates a cache which stores results without listening for
+// changed usages
+func enabledCache(size int, maxRows int, ttl time.Duration) *ResultCache {
+	cache := NewResultCache(size, maxRows, ttl)
+	cache.setEnabled(true)
+	return cache
+}
+
+// loaderOf returns a loader returning the value with the rows and counts its
+// executions
+func loaderOf(value any, rows int, calls *atomic.Int32) func(context.Context) (any, int, error) {
+	return func(context.Context) (any, int, error) {
+		calls.Add(1)
+		return value, rows, nil
+	}
+}
+
+func TestResultCache_LRU(t *testing.T) {
+	cache := enabledCache(2, 100, time.Minute)
+	var calls atomic.Int32
+
+	_, _ = cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+	_, _ = cache.load(context.Background(), "b", loaderOf("b", 1, &calls))
+	// uses a, which makes b the least recently used result
+	_, _ = cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+	_, _ = cache.load(context.Background(), "c", loaderOf("c", 1, &calls))
+
+	_, found := cache.get("b")
+	assert.False(t, found)
+	_, found = cache.get("a")
+	assert.True(t, found)
+	_, found = cache.get("c")
+	assert.True(t, found)
+
+	assert.EqualValues(t, 3, calls.Load())
+	stats := cache.Stats()
+	assert.EqualValues(t, 1, stats.Hits)
+	assert.EqualValues(t, 3, stats.Misses)
+	assert.EqualValues(t, 1, stats.Evictions)
+	assert.Equal(t, 2, stats.Entries)
+}
+
+func TestResultCache_Rows(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    []int
+		entries int
+		total   int
+	}{
+		{"Within_Limit", []int{4, 6}, 2, 10},
+		{"Evicts_Oldest", []int{4, 6, 5}, 2, 11},
+		{"Evicts_Several", []int{3, 3, 3, 10}, 1, 10},
+		{"Skips_Large_Result", []int{4, 13}, 1, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cache := enabledCache(10, 12, time.Minute)
+			var calls atomic.Int32
+			for i, rows := range test.rows {
+				key := string(rune('a' + i))
+				_, _ = cache.load(context.Background(), key, loaderOf(key, rows, &calls))
+			}
+
+			stats := cache.Stats()
+			assert.Equal(t, test.entries, stats.Entries)
+			assert.Equal(t, test.total, stats.Rows)
+		})
+	}
+}
+
+func TestResultCache_Replace(t *testing.T) {
+	cache := enabledCache(10, 12, time.Minute)
+
+	cache.add("a", "old", 8, 1)
+	cache.add("a", "new", 2, 1)
+
+	value, found := cache.get("a")
+	assert.True(t, found)
+	assert.Equal(t, "new", value)
+	assert.Equal(t, 2, cache.Stats().Rows)
+}
+
+func TestResultCache_TTL(t *testing.T) {
+	cache := enabledCache(10, 100, 20*time.Millisecond)
+	var calls atomic.Int32
+
+	_, _ = cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+	_, _ = cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+	assert.EqualValues(t, 1, calls.Load())
+
+	time.Sleep(30 * time.Millisecond)
+	_, found := cache.get("a")
+	assert.False(t, found)
+	assert.Equal(t, 0, cache.Stats().Entries)
+	assert.Equal(t, 0, cache.Stats().Rows)
+
+	_, _ = cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestResultCache_Singleflight(t *testing.T) {
+	cache := enabledCache(10, 100, time.Minute)
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	loader := func(context.Context) (any, int, error) {
+		calls.Add(1)
+		<-release
+		return "a", 1, nil
+	}
+
+	var wg sync.WaitGroup
+	values := make([]any, 10)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _ = cache.load(context.Background(), "a", loader)
+		}()
+	}
+
+	// waits until the first loader runs, so the other loads share it
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, calls.Load())
+	for _, value := range values {
+		assert.Equal(t, "a", value)
+	}
+}
+
+func TestResultCache_Errors(t *testing.T) {
+	cache := enabledCache(10, 100, time.Minute)
+	loadErr := errors.New("query failed")
+
+	_, err := cache.load(context.Background(), "a", func(context.Context) (any, int, error) {
+		return nil, 0, loadErr
+	})
+	assert.ErrorIs(t, err, loadErr)
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestResultCache_Purge(t *testing.T) {
+	cache := enabledCache(10, 100, time.Minute)
+	var calls atomic.Int32
+
+	_, _ = cache.load(context.Background(), "a", loaderOf("a", 3, &calls))
+	cache.Purge()
+
+	stats := cache.Stats()
+	assert.Equal(t, 0, stats.Entries)
+	assert.Equal(t, 0, stats.Rows)
+	assert.EqualValues(t, 2, stats.Invalidations)
+
+	// a result loaded while the cache is purged may be outdated and is not
+	// stored
+	_, _ = cache.load(context.Background(), "b", func(context.Context) (any, int, error) {
+		cache.Purge()
+		return "b", 1, nil
+	})
+	_, found := cache.get("b")
+	assert.False(t, found)
+}
+
+func TestResultCache_Disabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache *ResultCache
+	}{
+		{"Not_Listening", NewResultCache(10, 100, time.Minute)},
+		{"Zero_Size", enabledCache(0, 100, time.Minute)},
+		{"Zero_Rows", enabledCache(10, 0, time.Minute)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			_, _ = test.cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+			value, _ := test.cache.load(context.Background(), "a", loaderOf("a", 1, &calls))
+
+			assert.Equal(t, "a", value)
+			assert.EqualValues(t, 2, calls.Load())
+			assert.Equal(t, 0, test.cache.Stats().Entries)
+		})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	older := WithDataVersion(context.Background(), pgtype.Timestamptz{Time: time.Unix(100, 0), Valid: true})
+	newer := WithDataVersion(context.Background(), pgtype.Timestamptz{Time: time.Unix(200, 0), Valid: true})
+	var dst []int
+
+	key, err := cacheKey(older, &dst, "SELECT $1", []any{1})
+	assert.NoError(t, err)
+	sameKey, _ := cacheKey(older, &dst, "SELECT $1", []any{1})
+	assert.Equal(t, key, sameKey)
+
+	// a result of an older data version is not reused
+	newerKey, _ := cacheKey(newer, &dst, "SELECT $1", []any{1})
+	assert.NotEqual(t, key, newerKey)
+	otherArgsKey, _ := cacheKey(older, &dst, "SELECT $1", []any{2})
+	assert.NotEqual(t, key, otherArgsKey)
+
+	// queries without a data version are not cached
+	_, err = cacheKey(context.Background(), &dst, "SELECT $1", []any{1})
+	assert.ErrorIs(t, err, errMissingDataVersion)
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"io/fs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/qustavo/dotsql"
+	"github.com/rs/zerolog/log"
+
+	"microservice/internal/config"
+	"microservice/resources"
+)
+
+// Open connects the Pool to the database and loads the prepared sql queries.
+// The Pool is configured using the config.Current configuration. It is used
+// by the commands which only need the connection, every other caller uses
+// Connect
+func Open() {
+	l := log.With().Str("package", "internal/db").Logger()
+	l.Debug().Msg("connecting to the database")
+
+	poolConfig, err := pgxpool.ParseConfig("")
+	if err != nil {
+		l.Fatal().Err(err).Msg("could not parse database configuration")
+	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
+	if settings := config.Current.Database; settings.MaxConnections > 0 {
+		poolConfig.MaxConns = settings.MaxConnections
+	}
+	poolConfig.MinConns = config.Current.Database.MinConnections
+	if timeout := config.Current.Database.ConnectTimeout; timeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = timeout
+	}
+	Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		l.Fatal().Err(err).Msg("could not connect to database")
+	}
+	err = Pool.Ping(context.Background())
+	if err != nil {
+		l.Fatal().Err(err).Msg("could not ping database")
+	}
+	l.Debug().Msg("connected to the database")
+
+	l.Debug().Msg("loading prepared sql queries")
+	files, err := fs.ReadDir(resources.QueryFiles, ".")
+	if err != nil {
+		l.Fatal().Err(err).Msg("could not load queries")
+	}
+	var instances []*dotsql.DotSql
+	for _, queryFile := range files {
+		fd, err := resources.QueryFiles.Open(queryFile.Name())
+		if err != nil {
+			l.Fatal().Err(err).Msg("could not open query file")
+		}
+		instance, err := dotsql.Load(fd)
+		if err != nil {
+			l.Fatal().Err(err).Msg("could not load query file")
+		}
+		instances = append(instances, instance)
+	}
+	Queries = dotsql.Merge(instances...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load prepared queries")
+	}
+	for name := range Queries.QueryMap() {
+		query, err := Queries.Raw(name)
+		if err != nil {
+			l.Fatal().Err(err).Msg("could not read prepared query")
+		}
+		queryNames[query] = name
+	}
+	registerMetrics()
+}
+
+// Connect opens the database and prepares it for the service. If configured,
+// the pending migrations are applied. The Cache is configured using the
+// config.Current configuration. It needs to be called before any query is
+// executed
+func Connect() {
+	l := log.With().Str("package", "internal/db").Logger()
+	Open()
+
+	if config.Current.Database.MigrateOnStart {
+		l.Debug().Msg("applying pending migrations")
+		migrations, err := MigrateUp(context.Background())
+		if err != nil {
+			l.Fatal().Err(err).Msg("could not apply migrations")
+		}
+		for _, migration := range migrations {
+			l.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("applied migration")
+		}
+	}
+
+	Cache = NewResultCache(config.Current.Cache.Size, config.Current.Cache.MaxRows, config.Current.Cache.TTL)
+}
@@ -4,9 +4,8 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// This file contains the connection to the database which is automatically
-// initialized on import/app startup
+// This file contains the connection to the database which is initialized
+// by Connect at the app startup
 
-// Pool is automatically initialized at the app startup using the init
-// function in the internal package
+// Pool is initialized at the app startup by Connect
 var Pool *pgxpool.Pool
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"microservice/internal/metrics"
+)
+
+// This file contains the metrics describing the state of the Pool and the
+// Cache
+
+var (
+	poolConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "db", "pool_connections"),
+		"Connections of the database pool by their state",
+		[]string{"state"}, nil,
+	)
+	poolMaxConnectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "db", "pool_max_connections"),
+		"Maximum number of connections of the database pool",
+		nil, nil,
+	)
+	poolAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "db", "pool_acquires_total"),
+		"Connections acquired from the database pool",
+		nil, nil,
+	)
+	poolEmptyAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "db", "pool_empty_acquires_total"),
+		"Acquires which needed to wait for a connection of the database pool",
+		nil, nil,
+	)
+	poolCanceledAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "db", "pool_canceled_acquires_total"),
+		"Acquires which have been canceled while waiting for a connection",
+		nil, nil,
+	)
+	poolAcquireDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "db", "pool_acquire_duration_seconds_total"),
+		"Total time spent waiting for connections of the database pool",
+		nil, nil,
+	)
+	cacheRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "cache", "requests_total"),
+		"Lookups of query results in the cache by their result",
+		[]string{"result"}, nil,
+	)
+	cacheEvictionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "cache", "evictions_total"),
+		"Query results evicted from the cache as it was full",
+		nil, nil,
+	)
+	cacheInvalidationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "cache", "invalidations_total"),
+		"Purges of the cache due to changed usages",
+		nil, nil,
+	)
+	cacheEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "cache", "entries"),
+		"Query results currently stored in the cache",
+		nil, nil,
+	)
+	cacheRowsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "cache", "rows"),
+		"Rows of the query results currently stored in the cache",
+		nil, nil,
+	)
+)
+
+// collector reads the statistics of the Pool and the Cache every time the
+// metrics are collected
+type collector struct{}
+
+func (collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolConnectionsDesc
+	ch <- poolMaxConnectionsDesc
+	ch <- poolAcquiresDesc
+	ch <- poolEmptyAcquiresDesc
+	ch <- poolCanceledAcquiresDesc
+	ch <- poolAcquireDurationDesc
+	ch <- cacheRequestsDesc
+	ch <- cacheEvictionsDesc
+	ch <- cacheInvalidationsDesc
+	ch <- cacheEntriesDesc
+	ch <- cacheRowsDesc
+}
+
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	pool := Pool.Stat()
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(pool.AcquiredConns()), "acquired")
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(pool.IdleConns()), "idle")
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(pool.ConstructingConns()), "constructing")
+	ch <- prometheus.MustNewConstMetric(poolMaxConnectionsDesc, prometheus.GaugeValue, float64(pool.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(poolAcquiresDesc, prometheus.CounterValue, float64(pool.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquiresDesc, prometheus.CounterValue, float64(pool.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquiresDesc, prometheus.CounterValue, float64(pool.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue, pool.AcquireDuration().Seconds())
+
+	cache := Cache.Stats()
+	ch <- prometheus.MustNewConstMetric(cacheRequestsDesc, prometheus.CounterValue, float64(cache.Hits), "hit")
+	ch <- prometheus.MustNewConstMetric(cacheRequestsDesc, prometheus.CounterValue, float64(cache.Misses), "miss")
+	ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(cache.Evictions))
+	ch <- prometheus.MustNewConstMetric(cacheInvalidationsDesc, prometheus.CounterValue, float64(cache.Invalidations))
+	ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(cache.Entries))
+	ch <- prometheus.MustNewConstMetric(cacheRowsDesc, prometheus.GaugeValue, float64(cache.Rows))
+}
+
+// registerMetrics registers the collector in the metrics.Registry
+func registerMetrics() {
+	metrics.Registry.MustRegister(collector{})
+}
@@ -0,0 +1,358 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"microservice/resources"
+)
+
+// This file contains the versioned migrations of the database schema. The
+// applied versions are recorded in the database and every command changing
+// the schema holds an advisory lock, which allows multiple instances of the
+// service to apply the migrations at their startup
+
+// migrationFilePattern matches the names of the migration files and captures
+// their version, name and direction
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// noTransactionMarker is the first line of migration files which can't be
+// executed in a transaction, e.g., as they create an index concurrently. These
+// files may only contain a single statement, as multiple statements are
+// executed in an implicit transaction by the database
+const noTransactionMarker = "-- migrate: no-transaction"
+
+// conditionMarker prefixes the condition of migration files which may only be
+// executed in some databases, e.g., as they depend on an installed extension.
+// The statements are only executed if the query following the marker returns
+// true, but the migration is recorded regardless. The condition needs to be
+// placed in the leading comment lines of the file
+const conditionMarker = "-- migrate: if "
+
+// execer is implemented by the connections and transactions executing the
+// migrations
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Migration contains the statements of a single schema version
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus contains a migration and the time it has been applied. If
+// the migration is pending, AppliedAt is nil. Migrations which have been
+// applied, but are unknown to this version of the service, contain no
+// statements
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// appliedMigration is a row of the table recording the applied migrations
+type appliedMigration struct {
+	Version   int       `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Migrations reads the migrations from resources.MigrationFiles sorted by
+// their version. Every version needs an up and a down migration
+func Migrations() ([]Migration, error) {
+	return parseMigrations(resources.MigrationFiles)
+}
+
+// parseMigrations reads the migrations from the migrations directory of the
+// file system
+func parseMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.ReadDir(fsys, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[int]*Migration{}
+	for _, file := range files {
+		parts := migrationFilePattern.FindStringSubmatch(file.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("invalid migration file name '%s'", file.Name())
+		}
+		version, _ := strconv.Atoi(parts[1])
+
+		contents, err := fs.ReadFile(fsys, path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, found := migrations[version]
+		if !found {
+			migration = &Migration{Version: version, Name: parts[2]}
+			migrations[version] = migration
+		}
+		if migration.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has multiple names", version)
+		}
+		if parts[3] == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	var sorted []Migration
+	for _, migration := range migrations {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d needs an up and a down migration", migration.Version)
+		}
+		sorted = append(sorted, *migration)
+	}
+	slices.SortFunc(sorted, func(a, b Migration) int { return a.Version - b.Version })
+	return sorted, nil
+}
+
+// MigrateUp applies every pending migration in the order of their versions
+// and returns the applied migrations
+func MigrateUp(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		statuses, err := migrationStatuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pendingMigrations(statuses) {
+			if err := runMigration(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown reverts the supplied number of applied migrations, starting
+// with the latest version, and returns the reverted migrations
+func MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		statuses, err := migrationStatuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		migrations, err := revertedMigrations(statuses, steps)
+		if err != nil {
+			return err
+		}
+		for _, migration := range migrations {
+			if err := runMigration(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses returns the known and applied migrations sorted by their
+// version
+func MigrationStatuses(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := withMigrationLock(ctx, func(conn *pgxpool.Conn) error {
+		var err error
+		statuses, err = migrationStatuses(ctx, conn)
+		return err
+	})
+	return statuses, err
+}
+
+// withMigrationLock runs the function on a dedicated connection while it
+// holds the advisory lock of the migrations. The table recording the applied
+// migrations is created before the function is called
+func withMigrationLock(ctx context.Context, function func(conn *pgxpool.Conn) error) error {
+	conn, err := Pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	return holdMigrationLock(ctx, conn, func() error {
+		return function(conn)
+	})
+}
+
+// holdMigrationLock runs the function while the connection holds the
+// advisory lock of the migrations. The lock is released even if the function
+// fails
+func holdMigrationLock(ctx context.Context, conn execer, function func() error) error {
+	for _, name := range []string{"lock-migrations", "create-migration-table"} {
+		query, err := Queries.Raw(name)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Exec(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	err := function()
+
+	query, unlockErr := Queries.Raw("unlock-migrations")
+	if unlockErr == nil {
+		_, unlockErr = conn.Exec(context.WithoutCancel(ctx), query)
+	}
+	return errors.Join(err, unlockErr)
+}
+
+// migrationStatuses combines the known migrations with the applied versions
+// recorded in the database
+func migrationStatuses(ctx context.Context, conn *pgxpool.Conn) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := Queries.Raw("get-applied-migrations")
+	if err != nil {
+		return nil, err
+	}
+	var applied []appliedMigration
+	if err := pgxscan.Select(ctx, conn, &applied, query); err != nil {
+		return nil, err
+	}
+	return combineStatuses(migrations, applied), nil
+}
+
+// combineStatuses combines the known migrations with the applied versions.
+// Applied versions which are unknown to this version of the service are
+// included without statements
+func combineStatuses(migrations []Migration, applied []appliedMigration) []MigrationStatus {
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration})
+	}
+	for _, version := range applied {
+		index := slices.IndexFunc(statuses, func(status MigrationStatus) bool {
+			return status.Version == version.Version
+		})
+		if index < 0 {
+			statuses = append(statuses, MigrationStatus{Migration: Migration{Version: version.Version, Name: version.Name}})
+			index = len(statuses) - 1
+		}
+		statuses[index].AppliedAt = &version.AppliedAt
+	}
+	slices.SortFunc(statuses, func(a, b MigrationStatus) int { return a.Version - b.Version })
+	return statuses
+}
+
+// pendingMigrations returns the migrations which have not been applied yet in
+// the order they need to be applied
+func pendingMigrations(statuses []MigrationStatus) []Migration {
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending
+}
+
+// revertedMigrations returns up to steps applied migrations, starting with
+// the latest version, in the order they need to be reverted. If one of them is
+// unknown to this version of the service, none of them is returned
+func revertedMigrations(statuses []MigrationStatus, steps int) ([]Migration, error) {
+	var reverted []Migration
+	for _, status := range slices.Backward(statuses) {
+		if len(reverted) == steps {
+			break
+		}
+		if status.AppliedAt == nil {
+			continue
+		}
+		if status.Down == "" {
+			return nil, fmt.Errorf("migration %d is unknown to this version and can't be reverted", status.Version)
+		}
+		reverted = append(reverted, status.Migration)
+	}
+	return reverted, nil
+}
+
+// runMigration applies or reverts the migration and records the change in a
+// single transaction. Migration files starting with the noTransactionMarker
+// are executed and recorded without a transaction
+func runMigration(ctx context.Context, conn *pgxpool.Conn, migration Migration, up bool) error {
+	statements, recordName, recordArgs := migration.Down, "delete-migration", []any{migration.Version}
+	if up {
+		statements, recordName, recordArgs = migration.Up, "insert-migration", []any{migration.Version, migration.Name}
+	}
+
+	record, err := Queries.Raw(recordName)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(statements, noTransactionMarker) {
+		err = execMigration(ctx, conn, statements, record, recordArgs)
+	} else {
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			return execMigration(ctx, tx, statements, record, recordArgs)
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// execMigration executes the statements of the migration, if its condition
+// is met, and records the change
+func execMigration(ctx context.Context, conn execer, statements string, record string, recordArgs []any) error {
+	apply := true
+	if condition := migrationCondition(statements); condition != "" {
+		if err := conn.QueryRow(ctx, condition).Scan(&apply); err != nil {
+			return fmt.Errorf("unable to evaluate condition: %w", err)
+		}
+	}
+
+	if apply {
+		if _, err := conn.Exec(ctx, statements); err != nil {
+			return err
+		}
+	}
+	_, err := conn.Exec(ctx, record, recordArgs...)
+	return err
+}
+
+// migrationCondition returns the query following the conditionMarker in the
+// leading comment lines of the statements. If the statements are not
+// conditional, an empty string is returned
+func migrationCondition(statements string) string {
+	for _, line := range strings.Split(statements, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+		if condition, found := strings.CutPrefix(line, conditionMarker); found {
+			return condition
+		}
+	}
+	return ""
+}
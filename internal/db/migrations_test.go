@@ -0,0 +1,331 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/qustavo/dotsql"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingConn records the executed statements and fails the statement
+// matching failOn. Queried rows contain the condition
+type recordingConn struct {
+	statements []string
+	failOn     string
+	condition  bool
+}
+
+func (c *recordingConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.statements = append(c.statements, sql)
+	if sql == c.failOn {
+		return pgconn.CommandTag{}, errors.New("statement failed")
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *recordingConn) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	c.statements = append(c.statements, sql)
+	if sql == c.failOn {
+		return conditionRow{err: errors.New("query failed")}
+	}
+	return conditionRow{value: c.condition}
+}
+
+// conditionRow is a queried row containing a single boolean
+type conditionRow struct {
+	value bool
+	err   error
+}
+
+func (r conditionRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*bool) = r.value
+	return nil
+}
+
+// useLockQueries replaces the Queries with the queries used to hold the
+// migration lock for the test
+func useLockQueries(t *testing.T) {
+	t.Helper()
+	queries, err := dotsql.LoadFromString(`
+-- name: lock-migrations
+LOCK;
+
+-- name: create-migration-table
+CREATE;
+
+-- name: unlock-migrations
+UNLOCK;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := Queries
+	Queries = queries
+	t.Cleanup(func() { Queries = previous })
+}
+
+func TestHoldMigrationLock(t *testing.T) {
+	functionErr := errors.New("function failed")
+	tests := []struct {
+		name       string
+		failOn     string
+		function   error
+		called     bool
+		statements []string
+		error      string
+	}{
+		{"Success", "", nil, true, []string{"LOCK;", "CREATE;", "FUNCTION", "UNLOCK;"}, ""},
+		{"Function_Fails", "", functionErr, true, []string{"LOCK;", "CREATE;", "FUNCTION", "UNLOCK;"}, "function failed"},
+		{"Lock_Fails", "LOCK;", nil, false, []string{"LOCK;"}, "statement failed"},
+		{"Table_Fails", "CREATE;", nil, false, []string{"LOCK;", "CREATE;"}, "statement failed"},
+		{"Unlock_Fails", "UNLOCK;", nil, true, []string{"LOCK;", "CREATE;", "FUNCTION", "UNLOCK;"}, "statement failed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useLockQueries(t)
+			conn := &recordingConn{failOn: test.failOn}
+
+			called := false
+			err := holdMigrationLock(context.Background(), conn, func() error {
+				called = true
+				conn.statements = append(conn.statements, "FUNCTION")
+				return test.function
+			})
+
+			assert.Equal(t, test.called, called)
+			assert.Equal(t, test.statements, conn.statements)
+			if test.error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.error)
+			}
+		})
+	}
+}
+
+func TestParseMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int
+		error    string
+	}{
+		{
+			name: "Sorted_By_Version",
+			files: fstest.MapFS{
+				"migrations/0010_later.up.sql":    {Data: []byte("UP 10;")},
+				"migrations/0010_later.down.sql":  {Data: []byte("DOWN 10;")},
+				"migrations/0002_second.up.sql":   {Data: []byte("UP 2;")},
+				"migrations/0002_second.down.sql": {Data: []byte("DOWN 2;")},
+			},
+			versions: []int{2, 10},
+		},
+		{
+			name: "Missing_Down_Migration",
+			files: fstest.MapFS{
+				"migrations/0001_first.up.sql": {Data: []byte("UP 1;")},
+			},
+			error: "migration 1 needs an up and a down migration",
+		},
+		{
+			name: "Multiple_Names",
+			files: fstest.MapFS{
+				"migrations/0001_first.up.sql":   {Data: []byte("UP 1;")},
+				"migrations/0001_other.down.sql": {Data: []byte("DOWN 1;")},
+			},
+			error: "migration 1 has multiple names",
+		},
+		{
+			name: "Invalid_File_Name",
+			files: fstest.MapFS{
+				"migrations/first.sql": {Data: []byte("UP 1;")},
+			},
+			error: "invalid migration file name 'first.sql'",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			migrations, err := parseMigrations(test.files)
+			if test.error != "" {
+				assert.EqualError(t, err, test.error)
+				return
+			}
+			assert.NoError(t, err)
+
+			var versions []int
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			assert.Equal(t, test.versions, versions)
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	assert.NoError(t, err)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "the versions need to be consecutive")
+	}
+}
+
+// statusesOf combines the migrations with the applied versions for the
+// status tests
+func statusesOf(known []int, applied []int) []MigrationStatus {
+	var migrations []Migration
+	for _, version := range known {
+		migrations = append(migrations, Migration{Version: version, Name: "known", Up: "UP;", Down: "DOWN;"})
+	}
+	var appliedMigrations []appliedMigration
+	for _, version := range applied {
+		appliedMigrations = append(appliedMigrations, appliedMigration{Version: version, Name: "applied", AppliedAt: time.Now()})
+	}
+	return combineStatuses(migrations, appliedMigrations)
+}
+
+func versionsOf(migrations []Migration) []int {
+	var versions []int
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestCombineStatuses(t *testing.T) {
+	statuses := statusesOf([]int{1, 2, 3, 5}, []int{1, 2, 4})
+
+	var versions []int
+	var pending []bool
+	for _, status := range statuses {
+		versions = append(versions, status.Version)
+		pending = append(pending, status.AppliedAt == nil)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, versions)
+	assert.Equal(t, []bool{false, false, true, false, true}, pending)
+
+	// the applied version 4 is unknown to this version of the service
+	assert.Equal(t, "applied", statuses[3].Name)
+	assert.Empty(t, statuses[3].Up)
+	assert.Empty(t, statuses[3].Down)
+	assert.Equal(t, "known", statuses[2].Name)
+}
+
+func TestPendingMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		known   []int
+		applied []int
+		pending []int
+	}{
+		{"Fresh_Database", []int{1, 2, 3}, nil, []int{1, 2, 3}},
+		{"Up_To_Date", []int{1, 2, 3}, []int{1, 2, 3}, nil},
+		{"New_Migrations", []int{1, 2, 3}, []int{1}, []int{2, 3}},
+		{"Gap", []int{1, 2, 3}, []int{1, 3}, []int{2}},
+		{"Unknown_Applied_Version", []int{1, 2}, []int{1, 4}, []int{2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pending := pendingMigrations(statusesOf(test.known, test.applied))
+			assert.Equal(t, test.pending, versionsOf(pending))
+		})
+	}
+}
+
+func TestRevertedMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		known    []int
+		applied  []int
+		steps    int
+		reverted []int
+		error    string
+	}{
+		{"Latest", []int{1, 2, 3}, []int{1, 2, 3}, 1, []int{3}, ""},
+		{"Several_Steps", []int{1, 2, 3}, []int{1, 2, 3}, 2, []int{3, 2}, ""},
+		{"More_Steps_Than_Applied", []int{1, 2, 3}, []int{1, 2}, 5, []int{2, 1}, ""},
+		{"Skips_Pending", []int{1, 2, 3}, []int{1, 3}, 2, []int{3, 1}, ""},
+		{"Nothing_Applied", []int{1, 2}, nil, 1, nil, ""},
+		{"Unknown_Applied_Version", []int{1, 2}, []int{1, 2, 4}, 1, nil, "migration 4 is unknown to this version and can't be reverted"},
+		{"Unknown_Version_In_Steps", []int{1, 2, 4}, []int{1, 2, 3, 4}, 2, nil, "migration 3 is unknown to this version and can't be reverted"},
+		{"Unknown_Version_Beyond_Steps", []int{1, 2, 4}, []int{1, 2, 3, 4}, 1, []int{4}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reverted, err := revertedMigrations(statusesOf(test.known, test.applied), test.steps)
+			if test.error != "" {
+				assert.EqualError(t, err, test.error)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.reverted, versionsOf(reverted))
+		})
+	}
+}
+
+func TestExecMigration(t *testing.T) {
+	conn := &recordingConn{}
+	err := execMigration(context.Background(), conn, "UP;", "RECORD;", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"UP;", "RECORD;"}, conn.statements)
+
+	// a failed migration is not recorded
+	conn = &recordingConn{failOn: "UP;"}
+	err = execMigration(context.Background(), conn, "UP;", "RECORD;", nil)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"UP;"}, conn.statements)
+}
+
+func TestExecMigration_Condition(t *testing.T) {
+	statements := "-- migrate: no-transaction\n-- migrate: if SELECT TRUE\nUP;"
+	tests := []struct {
+		name       string
+		condition  bool
+		failOn     string
+		statements []string
+		error      string
+	}{
+		{"Met", true, "", []string{"SELECT TRUE", statements, "RECORD;"}, ""},
+		{"Not_Met", false, "", []string{"SELECT TRUE", "RECORD;"}, ""},
+		{"Query_Fails", true, "SELECT TRUE", []string{"SELECT TRUE"}, "unable to evaluate condition: query failed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := &recordingConn{condition: test.condition, failOn: test.failOn}
+			err := execMigration(context.Background(), conn, statements, "RECORD;", nil)
+			if test.error != "" {
+				assert.EqualError(t, err, test.error)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.statements, conn.statements)
+		})
+	}
+}
+
+func TestMigrationCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements string
+		condition  string
+	}{
+		{"Unconditional", "-- migrate: no-transaction\nUP;", ""},
+		{"First_Line", "-- migrate: if SELECT TRUE\nUP;", "SELECT TRUE"},
+		{"After_Comments", "-- migrate: no-transaction\n-- creates the index\n-- migrate: if SELECT TRUE\nUP;", "SELECT TRUE"},
+		{"After_Statements", "UP;\n-- migrate: if SELECT TRUE", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.condition, migrationCondition(test.statements))
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"microservice/internal/metrics"
+)
+
+// This file contains the tracer recording the execution time and row counts
+// of the sql queries and creating a span for each of them
+
+// dynamicQuery is used as query name for queries which are neither read from
+// the query files nor contain a name comment
+const dynamicQuery = "dynamic"
+
+// nameComment prefixes the name of the query in the queries built at runtime,
+// following the naming of the queries in the query files
+const nameComment = "-- name: "
+
+// AttributeQueryName contains the name of the query on its span
+const AttributeQueryName = attribute.Key("db.query.name")
+
+// queryNames maps the sql of the queries read from the query files to their
+// names. It is filled once the queries have been loaded
+var queryNames = map[string]string{}
+
+// tracer creates the spans of the queries. It uses the global tracer provider
+// which is configured at the app startup
+var tracer = otel.Tracer("microservice/internal/db")
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	name  string
+	start time.Time
+	span  trace.Span
+}
+
+// queryName outputs the comment naming a query built at runtime
+func queryName(name string) string {
+	return nameComment + name + "\n"
+}
+
+// lookupQueryName returns the name of the query from the query files or the
+// name comment in the first line of the query
+func lookupQueryName(sql string) string {
+	if name, found := queryNames[sql]; found {
+		return name
+	}
+	if line, _, found := strings.Cut(sql, "\n"); found && strings.HasPrefix(line, nameComment) {
+		return strings.TrimPrefix(line, nameComment)
+	}
+	return dynamicQuery
+}
+
+// queryTracer records the metrics.QueryDuration and metrics.QueryRows of the
+// queries and copies executed on the connections of the Pool. Each of them
+// gets a span which is a child of the span contained in the context
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return startQuery(ctx, lookupQueryName(data.SQL), semconv.DBQueryText(data.SQL))
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	endQuery(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+func (queryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	table := strings.Join(data.TableName, ".")
+	return startQuery(ctx, "copy-"+table, semconv.DBCollectionName(table))
+}
+
+func (queryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endQuery(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+// startQuery starts the span of the query and stores it together with the
+// start time of the query in the returned context
+func startQuery(ctx context.Context, name string, attributes ...attribute.KeyValue) context.Context {
+	attributes = append(attributes, semconv.DBSystemPostgreSQL, AttributeQueryName.String(name))
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	return context.WithValue(ctx, queryTraceKey{}, queryTrace{name: name, start: time.Now(), span: span})
+}
+
+// endQuery records the metrics of the query started with the context and
+// ends its span
+func endQuery(ctx context.Context, rows int64, err error) {
+	query, ok := ctx.Value(queryTraceKey{}).(queryTrace)
+	if !ok {
+		return
+	}
+	metrics.QueryDuration.
+		WithLabelValues(query.name, strconv.FormatBool(err == nil)).
+		Observe(time.Since(query.start).Seconds())
+	metrics.QueryRows.WithLabelValues(query.name).Add(float64(rows))
+
+	query.span.SetAttributes(attribute.Int64("db.response.rows", rows))
+	if err != nil {
+		query.span.RecordError(err)
+		query.span.SetStatus(codes.Error, err.Error())
+	}
+	query.span.End()
+}
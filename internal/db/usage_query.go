@@ -0,0 +1,350 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"microservice/structs"
+)
+
+// This file contains a small query builder which allows combining multiple
+// filters on the recorded water usages without concatenating user input into
+// the sql query
+
+// Column is a column of the water usage table which may be used in the
+// conditions of a UsageQuery
+type Column string
+
+const (
+	ColumnTime         Column = "time"
+	ColumnAmount       Column = "amount"
+	ColumnUsageType    Column = "usage_type"
+	ColumnConsumer     Column = "consumer"
+	ColumnMunicipality Column = "municipality"
+)
+
+// sortExpressions maps the sortable fields of the usage records to the
+// expressions the usage records are sorted by. The nullable columns are
+// coalesced to their zero value to allow the comparison of the sort keys when
+// continuing after a cursor
+var sortExpressions = map[string]string{
+	"time":       string(ColumnTime),
+	"amount":     string(ColumnAmount),
+	"consumerID": fmt.Sprintf("coalesce(%s, '00000000-0000-0000-0000-000000000000'::uuid)", ColumnConsumer),
+	"usageType":  fmt.Sprintf("coalesce(%s, '00000000-0000-0000-0000-000000000000'::uuid)", ColumnUsageType),
+	"ars":        fmt.Sprintf("coalesce(%s, '')", ColumnMunicipality),
+}
+
+// fieldColumns maps the fields of the usage records to the columns they are
+// read from
+var fieldColumns = map[string]Column{
+	"time":       ColumnTime,
+	"amount":     ColumnAmount,
+	"usageType":  ColumnUsageType,
+	"consumerID": ColumnConsumer,
+	"ars":        ColumnMunicipality,
+}
+
+// usageOrder contains the fields the usage records are sorted by if no other
+// sort order is requested. The fields are appended to every requested sort
+// order which keeps the order of the usage records deterministic
+var usageOrder = []structs.SortField{
+	{Field: "time"},
+	{Field: "consumerID"},
+	{Field: "usageType"},
+	{Field: "ars"},
+}
+
+// UsageQuery builds a parameterized query on the water usage table. Every
+// value supplied to the query is passed to the database as an argument and is
+// never written into the query itself
+type UsageQuery struct {
+	conditions []string
+	arguments  []any
+	cursor     *structs.UsageCursor
+	order      []structs.SortField
+	fields     []string
+	limit      *int
+	offset     *int
+
+	expandMunicipality bool
+	selectCursor       bool
+}
+
+// argument registers the value as a query argument and returns the
+// placeholder which references it in the query
+func (q *UsageQuery) argument(value any) string {
+	q.arguments = append(q.arguments, value)
+	return fmt.Sprintf("$%d", len(q.arguments))
+}
+
+// Where restricts the query to rows which contain the value in the column
+func (q *UsageQuery) Where(column Column, value any) {
+	q.conditions = append(q.conditions, fmt.Sprintf("%s = %s", column, q.argument(value)))
+}
+
+// WhereIn restricts the query to rows which contain one of the values in the
+// column. The values need to be supplied as a slice which is sent to the
+// database as an array
+func (q *UsageQuery) WhereIn(column Column, values any) {
+	q.conditions = append(q.conditions, fmt.Sprintf("%s = ANY(%s)", column, q.argument(values)))
+}
+
+// WherePrefix restricts the query to rows in which the value of the column
+// starts with the prefix
+func (q *UsageQuery) WherePrefix(column Column, prefix string) {
+	q.conditions = append(q.conditions, fmt.Sprintf("starts_with(%s, %s)", column, q.argument(prefix)))
+}
+
+// From restricts the query to usages recorded at or after the timestamp
+func (q *UsageQuery) From(from any) {
+	q.conditions = append(q.conditions, fmt.Sprintf("%s >= %s", ColumnTime, q.argument(from)))
+}
+
+// Until restricts the query to usages recorded before the timestamp
+func (q *UsageQuery) Until(until any) {
+	q.conditions = append(q.conditions, fmt.Sprintf("%s < %s", ColumnTime, q.argument(until)))
+}
+
+// After restricts the query to usages which are sorted after the usage the
+// cursor has been created for. The cursor is only applied when building the
+// query for the usage records and is ignored while counting the usages
+func (q *UsageQuery) After(cursor structs.UsageCursor) {
+	q.cursor = &cursor
+}
+
+// ExpandMunicipality adds the name of the municipality from the region
+// registry to the usage records as municipality_name
+func (q *UsageQuery) ExpandMunicipality() {
+	q.expandMunicipality = true
+}
+
+// OrderBy sorts the usage records by the supplied fields before sorting them
+// by the default order. The fields need to be one of structs.UsageFields
+func (q *UsageQuery) OrderBy(fields []structs.SortField) {
+	q.order = fields
+}
+
+// Select restricts the columns read from the database to the supplied fields.
+// The fields need to be one of structs.UsageFields. If no fields are supplied,
+// all columns are read
+func (q *UsageQuery) Select(fields []string) {
+	q.fields = fields
+}
+
+// SelectCursor adds the sort key of the last usage record of a page to the
+// usage record as cursor column, which allows continuing after the page
+// without selecting the sorted columns for every usage record. It is only
+// applied to paginated queries
+func (q *UsageQuery) SelectCursor() {
+	q.selectCursor = true
+}
+
+// Fields returns the fields selected by Select
+func (q *UsageQuery) Fields() []string {
+	return q.fields
+}
+
+// Paginate limits the number of returned rows and skips the number of rows
+// given by the offset
+func (q *UsageQuery) Paginate(limit int, offset int) {
+	q.limit = &limit
+	q.offset = &offset
+}
+
+// Paginated reports if the number of returned rows is limited by the query
+func (q *UsageQuery) Paginated() bool {
+	return q.limit != nil
+}
+
+// where returns the where clause for the conditions, including the WHERE
+// keyword. if no conditions are supplied, an empty string is returned
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ") + " "
+}
+
+// selection returns the selected columns of the query. If the cursor has been
+// selected, the sort key is only built for the last row of the page, which is
+// identified by its position in the ordered rows
+func (q *UsageQuery) selection(orderExpressions []string, arguments []any) (string, []any) {
+	columns := []string{"water_usage.*"}
+	if len(q.fields) > 0 {
+		columns = nil
+		for _, field := range structs.UsageFields {
+			if slices.Contains(q.fields, field) {
+				columns = append(columns, string(fieldColumns[field]))
+			}
+		}
+	}
+
+	if q.expandMunicipality {
+		columns = append(columns, "regions.name AS municipality_name")
+	}
+
+	if q.selectCursor && q.Paginated() {
+		arguments = append(arguments, *q.offset+*q.limit)
+		columns = append(columns, fmt.Sprintf(
+			"CASE WHEN row_number() OVER (ORDER BY %s) = $%d THEN json_build_object('t', %s, 'a', %s, 'c', %s, 'u', %s, 'm', %s) END AS cursor",
+			strings.Join(orderExpressions, ", "), len(arguments),
+			sortExpressions["time"], sortExpressions["amount"], sortExpressions["consumerID"], sortExpressions["usageType"], sortExpressions["ars"],
+		))
+	}
+	return strings.Join(columns, ", "), arguments
+}
+
+// SortOrder returns the requested sort order followed by the fields of the
+// default order which have not been requested
+func (q *UsageQuery) SortOrder() []structs.SortField {
+	order := slices.Clone(q.order)
+	for _, field := range usageOrder {
+		if !slices.ContainsFunc(order, func(f structs.SortField) bool { return f.Field == field.Field }) {
+			order = append(order, field)
+		}
+	}
+	return order
+}
+
+// cursorValue returns the value of the sort field stored in the cursor
+func cursorValue(cursor structs.UsageCursor, field string) any {
+	switch field {
+	case "time":
+		return cursor.Time
+	case "amount":
+		return cursor.Amount
+	case "consumerID":
+		return cursor.ConsumerID
+	case "usageType":
+		return cursor.UsageType
+	default:
+		return cursor.ARS
+	}
+}
+
+// cursorCondition returns the condition matching the usage records which are
+// sorted after the cursor. If all fields are sorted in the same direction, the
+// sort keys are compared as a single row. Otherwise, the sort keys are
+// compared one after another
+func cursorCondition(order []structs.SortField, cursor structs.UsageCursor, arguments []any) (string, []any) {
+	expressions := make([]string, len(order))
+	placeholders := make([]string, len(order))
+	comparators := make([]string, len(order))
+	for i, field := range order {
+		arguments = append(arguments, cursorValue(cursor, field.Field))
+		expressions[i] = sortExpressions[field.Field]
+		placeholders[i] = fmt.Sprintf("$%d", len(arguments))
+		comparators[i] = ">"
+		if field.Descending {
+			comparators[i] = "<"
+		}
+	}
+
+	if !slices.ContainsFunc(comparators, func(c string) bool { return c != comparators[0] }) {
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(expressions, ", "), comparators[0], strings.Join(placeholders, ", ")), arguments
+	}
+
+	alternatives := make([]string, len(order))
+	for i := range order {
+		var comparisons []string
+		for j := range i {
+			comparisons = append(comparisons, fmt.Sprintf("%s = %s", expressions[j], placeholders[j]))
+		}
+		comparisons = append(comparisons, fmt.Sprintf("%s %s %s", expressions[i], comparators[i], placeholders[i]))
+		alternatives[i] = "(" + strings.Join(comparisons, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", arguments
+}
+
+// Build outputs the query and the arguments that need to be supplied
+// together with the query. The usage records are sorted by the requested
+// fields followed by their time, consumer, usage type and municipality to
+// allow a stable pagination
+func (q *UsageQuery) Build() (string, []any) {
+	arguments := slices.Clone(q.arguments)
+	conditions := slices.Clone(q.conditions)
+	order := q.SortOrder()
+
+	if q.cursor != nil {
+		var condition string
+		condition, arguments = cursorCondition(order, *q.cursor, arguments)
+		conditions = append(conditions, condition)
+	}
+
+	orderExpressions := make([]string, len(order))
+	for i, field := range order {
+		orderExpressions[i] = sortExpressions[field.Field]
+		if field.Descending {
+			orderExpressions[i] += " DESC"
+		}
+	}
+
+	var selection string
+	selection, arguments = q.selection(orderExpressions, arguments)
+
+	var query strings.Builder
+	query.WriteString(queryName("usage-records"))
+	query.WriteString("SELECT " + selection + " FROM timeseries.water_usage ")
+	if q.expandMunicipality {
+		query.WriteString(fmt.Sprintf("LEFT JOIN registry.regions ON regions.ars = water_usage.%s ", ColumnMunicipality))
+	}
+	query.WriteString(where(conditions))
+	query.WriteString("ORDER BY " + strings.Join(orderExpressions, ", ") + " ")
+	if q.limit != nil {
+		arguments = append(arguments, *q.limit)
+		query.WriteString(fmt.Sprintf("LIMIT $%d ", len(arguments)))
+	}
+	if q.offset != nil {
+		arguments = append(arguments, *q.offset)
+		query.WriteString(fmt.Sprintf("OFFSET $%d", len(arguments)))
+	}
+	return strings.TrimSpace(query.String()), arguments
+}
+
+// BuildAggregation outputs a query aggregating the amounts of the matched
+// usages into time buckets of the supplied size and the arguments that need to
+// be supplied together with the query. The bucket needs to be a field accepted
+// by the date_trunc function of the database. The buckets start at midnight
+// in the supplied time zone
+func (q *UsageQuery) BuildAggregation(bucket string, timeZone string) (string, []any) {
+	arguments := slices.Clone(q.arguments)
+	arguments = append(arguments, bucket, timeZone)
+
+	var query strings.Builder
+	query.WriteString(queryName("usage-aggregation"))
+	query.WriteString(fmt.Sprintf("SELECT date_trunc($%d, %s, $%d) AS bucket, ", len(arguments)-1, ColumnTime, len(arguments)))
+	query.WriteString(fmt.Sprintf("sum(%[1]s) AS sum, avg(%[1]s) AS average, min(%[1]s) AS minimum, max(%[1]s) AS maximum, ", ColumnAmount))
+	query.WriteString("count(*) AS count ")
+	query.WriteString("FROM timeseries.water_usage ")
+	query.WriteString(where(q.conditions))
+	query.WriteString("GROUP BY bucket ORDER BY bucket")
+	return query.String(), arguments
+}
+
+// BuildRollup outputs a query summing the amounts of the matched usages per
+// region and the arguments that need to be supplied together with the query.
+// The regions are identified by the first characters of the ARS, the number of
+// characters is set by the length
+func (q *UsageQuery) BuildRollup(length int) (string, []any) {
+	arguments := slices.Clone(q.arguments)
+	arguments = append(arguments, length)
+
+	var query strings.Builder
+	query.WriteString(queryName("usage-rollup"))
+	query.WriteString(fmt.Sprintf("SELECT left(%s, $%d) AS ars, ", ColumnMunicipality, len(arguments)))
+	query.WriteString(fmt.Sprintf("sum(%[1]s) AS sum, avg(%[1]s) AS average, min(%[1]s) AS minimum, max(%[1]s) AS maximum, ", ColumnAmount))
+	query.WriteString("count(*) AS count ")
+	query.WriteString("FROM timeseries.water_usage ")
+	query.WriteString(where(q.conditions))
+	query.WriteString("GROUP BY ars ORDER BY ars")
+	return query.String(), arguments
+}
+
+// BuildCount outputs a query counting the matched usages and the arguments
+// that need to be supplied together with the query. The pagination settings
+// and the cursor are not applied to the count
+func (q *UsageQuery) BuildCount() (string, []any) {
+	return strings.TrimSpace(queryName("usage-count") + "SELECT count(*) FROM timeseries.water_usage " + where(q.conditions)), slices.Clone(q.arguments)
+}
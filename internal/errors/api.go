@@ -41,7 +41,14 @@ var ErrInvalidARS = types.ServiceError{
 	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
 	Status: 400,
 	Title:  "Invalid ARS",
-	Detail: "The ARS is not in a valid format",
+	Detail: "The ARS is not in a valid format. It needs to contain 2, 3, 5, 9 or 12 digits depending on the regional level and start with a Land code between 01 and 16. The errors name the invalid component",
+}
+
+var ErrUnknownUsageType = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.5",
+	Status: 404,
+	Title:  "Unknown Usage Type",
+	Detail: "No usage type with the supplied id exists",
 }
 
 var ErrEmptyUsageTypeID = types.ServiceError{
@@ -57,3 +64,136 @@ var ErrInvalidUsageTypeID = types.ServiceError{
 	Title:  "Invalid Usage Type ID",
 	Detail: "The usage type id is not in a valid format",
 }
+
+var ErrInvalidTimestamp = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Timestamp",
+	Detail: "The time range boundaries need to be supplied as RFC 3339 timestamps",
+}
+
+var ErrInvertedTimeRange = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Inverted Time Range",
+	Detail: "The start of the time range needs to be before the end of the time range",
+}
+
+var ErrInvalidRollupSettings = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Rollup Settings",
+	Detail: "The rollup parameter needs to be a boolean value",
+}
+
+var ErrInvalidSortField = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Sort Field",
+	Detail: "The usage records may only be sorted once by each of the fields: time, amount, usageType, consumerID, ars",
+}
+
+var ErrInvalidFields = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Fields",
+	Detail: "The selected fields may only contain each of the fields once: time, amount, usageType, consumerID, ars",
+}
+
+var ErrInvalidAggregationBucket = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Aggregation Bucket",
+	Detail: "The aggregation bucket needs to be one of: day, week, month, quarter, year",
+}
+
+var ErrInvalidTimeZone = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Time Zone",
+	Detail: "The time zone needs to be a name of the IANA time zone database, e.g., Europe/Berlin or UTC",
+}
+
+var ErrInvalidCursor = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Cursor",
+	Detail: "The pagination cursor is malformed. Please use the cursor returned by the previous page",
+}
+
+var ErrUnsupportedFormat = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Unsupported Output Format",
+	Detail: "The requested output format is not supported. Supported formats are: json, csv, ndjson, parquet",
+}
+
+var ErrInvalidExpansion = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Expansion",
+	Detail: "The expanded reference needs to be one of: municipality",
+}
+
+var ErrInvalidEnvelope = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Envelope Setting",
+	Detail: "The envelope parameter needs to be a boolean value",
+}
+
+var ErrInvalidCSVSettings = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid CSV Settings",
+	Detail: "The CSV delimiter needs to be a single character and the decimal separator needs to be either '.' or ','. Both may not be the same character",
+}
+
+var ErrMalformedUsageRecords = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Malformed Usage Records",
+	Detail: "The request body could not be parsed into usage records",
+}
+
+var ErrEmptyBatch = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Empty Batch",
+	Detail: "The batch does not contain any usage records",
+}
+
+var ErrBatchTooLarge = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.14",
+	Status: 413,
+	Title:  "Batch Too Large",
+	Detail: "The request body exceeds the size or the number of usage records accepted at once. Please split the usage records into multiple requests",
+}
+
+var ErrInvalidUsageRecords = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.21",
+	Status: 422,
+	Title:  "Invalid Usage Records",
+	Detail: "At least one usage record is invalid. No usage records have been stored. The errors list the issues of each invalid usage record",
+}
+
+var ErrMissingImportFile = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Missing Import File",
+	Detail: "The request does not contain a file in the 'file' field of the multipart form",
+}
+
+var ErrInvalidImportFile = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Import File",
+	Detail: "The uploaded file could not be read. Only CSV and XLSX files containing a header row with the mapped columns are supported",
+}
+
+var ErrInvalidImportSettings = types.ServiceError{
+	Type:   "https://www.rfc-editor.org/rfc/rfc9110#section-15.5.1",
+	Status: 400,
+	Title:  "Invalid Import Settings",
+	Detail: "The settings for the import are not valid",
+}
@@ -0,0 +1,277 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservice/internal/db"
+	"microservice/structs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/xuri/excelize/v2"
+)
+
+var ErrMissingColumn = errors.New("missing column")
+
+// Options contains the settings used while importing usage records from a
+// file
+type Options struct {
+	Format           string
+	Delimiter        rune
+	DecimalSeparator string
+	Sheet            string
+	TimeFormat       string
+	Columns          structs.ColumnMapping
+	DryRun           bool
+}
+
+// columnIndices contains the position of the mapped columns in the header
+// row. Optional columns which are not present are set to -1
+type columnIndices struct {
+	time, amount, usageType, consumerID, ars int
+}
+
+// Import converts the rows of the table into usage records and validates
+// them. Every invalid row is listed in the returned report. If the import is
+// not a dry run, all valid usage records are stored in a single transaction.
+// The returned error is only set if the import itself failed
+func Import(ctx context.Context, table [][]string, options Options) (structs.ImportReport, error) {
+	report := structs.ImportReport{
+		DryRun: options.DryRun,
+		Issues: []structs.ImportIssue{},
+	}
+
+	if len(table) == 0 {
+		return report, fmt.Errorf("%w: the file does not contain a header row", ErrMissingColumn)
+	}
+
+	columns, err := locateColumns(table[0], options.Columns)
+	if err != nil {
+		return report, err
+	}
+
+	if options.TimeFormat == "" {
+		options.TimeFormat = time.RFC3339
+	}
+
+	validator := NewValidator()
+	knownRecords := make(map[string]int)
+	var records []structs.UsageRecord
+	var recordRows []int
+
+	for index, row := range table[1:] {
+		// rows are counted like in spreadsheet applications which start at
+		// one and contain the header row
+		rowNumber := index + 2
+		if isEmptyRow(row) {
+			continue
+		}
+		report.Rows++
+
+		record, issues := parseRow(row, columns, options)
+
+		validationIssues, err := validator.ValidateRecord(ctx, record)
+		if err != nil {
+			return report, err
+		}
+		for _, issue := range validationIssues {
+			// unparsable timestamps have already been reported
+			if errors.Is(issue, ErrMissingTime) && cell(row, columns.time) != "" {
+				continue
+			}
+			issues = append(issues, issue)
+		}
+
+		if len(issues) == 0 {
+			key := recordKey(record)
+			if duplicateRow, isDuplicate := knownRecords[key]; isDuplicate {
+				issues = append(issues, fmt.Errorf("duplicate timestamp, the usage has already been recorded in row %d", duplicateRow))
+			} else {
+				knownRecords[key] = rowNumber
+			}
+		}
+
+		for _, issue := range issues {
+			report.Issues = append(report.Issues, structs.ImportIssue{Row: rowNumber, Message: issue.Error()})
+		}
+		if len(issues) == 0 {
+			records = append(records, record)
+			recordRows = append(recordRows, rowNumber)
+		}
+	}
+
+	// usages recorded by earlier imports are only detected after every row
+	// has been read to check all of them with a single query
+	recorded, err := recordedUsages(ctx, records)
+	if err != nil {
+		return report, err
+	}
+	for _, index := range slices.Backward(recorded) {
+		report.Issues = append(report.Issues, structs.ImportIssue{
+			Row:     recordRows[index],
+			Message: "duplicate timestamp, the usage has already been recorded",
+		})
+		records = slices.Delete(records, index, index+1)
+	}
+	slices.SortStableFunc(report.Issues, func(a, b structs.ImportIssue) int { return a.Row - b.Row })
+
+	report.Valid = len(records)
+	if options.DryRun || len(records) == 0 {
+		return report, nil
+	}
+
+	report.Inserted, err = Store(ctx, records)
+	return report, err
+}
+
+// locateColumns finds the mapped columns in the header row. The time, amount
+// and ARS columns are required
+func locateColumns(header []string, mapping structs.ColumnMapping) (columnIndices, error) {
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	columns := columnIndices{
+		time:       slices.Index(header, mapping.Time),
+		amount:     slices.Index(header, mapping.Amount),
+		usageType:  slices.Index(header, mapping.UsageType),
+		consumerID: slices.Index(header, mapping.ConsumerID),
+		ars:        slices.Index(header, mapping.ARS),
+	}
+
+	requiredColumns := []struct {
+		name  string
+		index int
+	}{
+		{mapping.Time, columns.time},
+		{mapping.Amount, columns.amount},
+		{mapping.ARS, columns.ars},
+	}
+	for _, column := range requiredColumns {
+		if column.index == -1 {
+			return columns, fmt.Errorf("%w: the header row does not contain the column '%s'", ErrMissingColumn, column.name)
+		}
+	}
+	return columns, nil
+}
+
+// parseRow converts the row into a usage record. The issues contain the
+// values which could not be parsed
+func parseRow(row []string, columns columnIndices, options Options) (structs.UsageRecord, []error) {
+	var record structs.UsageRecord
+	var issues []error
+
+	if rawTime := cell(row, columns.time); rawTime != "" {
+		timestamp, err := parseTime(rawTime, options.TimeFormat)
+		if err != nil {
+			issues = append(issues, fmt.Errorf("invalid time '%s'", rawTime))
+		} else {
+			record.Time = pgtype.Timestamptz{Time: timestamp, Valid: true}
+		}
+	}
+
+	rawAmount := cell(row, columns.amount)
+	amount, err := strconv.ParseFloat(strings.Replace(rawAmount, options.DecimalSeparator, ".", 1), 64)
+	if err != nil {
+		issues = append(issues, fmt.Errorf("non-numeric amount '%s'", rawAmount))
+	}
+	record.Amount = amount
+
+	record.UsageType = optionalCell(row, columns.usageType)
+	record.ConsumerID = optionalCell(row, columns.consumerID)
+	record.ARS = optionalCell(row, columns.ars)
+
+	return record, issues
+}
+
+// parseTime parses the timestamp using the time format. Spreadsheets store
+// timestamps as serial numbers which are converted as well
+func parseTime(rawTime string, timeFormat string) (time.Time, error) {
+	timestamp, err := time.Parse(timeFormat, rawTime)
+	if err == nil {
+		return timestamp, nil
+	}
+
+	serial, serialErr := strconv.ParseFloat(rawTime, 64)
+	if serialErr != nil {
+		return time.Time{}, err
+	}
+	return excelize.ExcelDateToTime(serial, false)
+}
+
+// recordedUsages returns the indices of the usage records which have already
+// been stored in the database, sorted in ascending order
+func recordedUsages(ctx context.Context, records []structs.UsageRecord) ([]int, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	q, err := db.Queries.Raw("find-recorded-usages")
+	if err != nil {
+		return nil, err
+	}
+
+	// the amount is not part of the key of a usage
+	times := make([]pgtype.Timestamptz, len(records))
+	consumerIDs := make([]*uuid.UUID, len(records))
+	usageTypes := make([]*uuid.UUID, len(records))
+	municipalities := make([]string, len(records))
+	for i, record := range records {
+		row := Row(record)
+		times[i] = record.Time
+		usageTypes[i] = row[2].(*uuid.UUID)
+		consumerIDs[i] = row[3].(*uuid.UUID)
+		municipalities[i] = *record.ARS
+	}
+
+	var indices []int
+	err = pgxscan.Select(ctx, db.Pool, &indices, q, times, consumerIDs, usageTypes, municipalities)
+	return indices, err
+}
+
+// recordKey identifies the usage recorded in the usage record
+func recordKey(record structs.UsageRecord) string {
+	return fmt.Sprintf("%d|%v|%v|%v",
+		record.Time.Time.UnixMicro(),
+		optionalValue(record.ConsumerID),
+		optionalValue(record.UsageType),
+		optionalValue(record.ARS),
+	)
+}
+
+func cell(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[index])
+}
+
+func optionalCell(row []string, index int) *string {
+	value := cell(row, index)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
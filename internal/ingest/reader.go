@@ -0,0 +1,79 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FileFormatCSV  = "csv"
+	FileFormatXLSX = "xlsx"
+)
+
+var ErrUnsupportedFileFormat = errors.New("unsupported file format")
+
+// utf8BOM is prepended to CSV files by some spreadsheet applications
+const utf8BOM = "\ufeff"
+
+// FileFormat determines the format of the file using its extension
+func FileFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FileFormatCSV, nil
+	case ".xlsx":
+		return FileFormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFileFormat
+	}
+}
+
+// ReadTable reads all rows of the file. The first row is expected to be the
+// header row
+func ReadTable(r io.Reader, options Options) ([][]string, error) {
+	switch options.Format {
+	case FileFormatCSV:
+		return readCSV(r, options.Delimiter)
+	case FileFormatXLSX:
+		return readXLSX(r, options.Sheet)
+	default:
+		return nil, ErrUnsupportedFileFormat
+	}
+}
+
+func readCSV(r io.Reader, delimiter rune) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	table, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(table) > 0 && len(table[0]) > 0 {
+		table[0][0] = strings.TrimPrefix(table[0][0], utf8BOM)
+	}
+	return table, nil
+}
+
+// readXLSX reads the raw cell values of the sheet. If no sheet is set, the
+// first sheet of the workbook is read
+func readXLSX(r io.Reader, sheet string) ([][]string, error) {
+	workbook, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer workbook.Close()
+
+	if sheet == "" {
+		sheet = workbook.GetSheetName(0)
+	}
+
+	return workbook.GetRows(sheet, excelize.Options{RawCellValue: true})
+}
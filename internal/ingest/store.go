@@ -0,0 +1,64 @@
+package ingest
+
+import (
+	"context"
+	"microservice/internal/db"
+	"microservice/structs"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// usageColumns contains the columns filled when storing usage records
+var usageColumns = []string{"time", "amount", "usage_type", "consumer", "municipality"}
+
+// copier is implemented by the database pool and transactions
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// Copy stores the validated usage records using the copy protocol of the
+// database and returns the number of stored usage records
+func Copy(ctx context.Context, conn copier, records []structs.UsageRecord) (int64, error) {
+	return conn.CopyFrom(ctx,
+		pgx.Identifier{"timeseries", "water_usage"},
+		usageColumns,
+		pgx.CopyFromSlice(len(records), func(i int) ([]any, error) {
+			return Row(records[i]), nil
+		}),
+	)
+}
+
+// Store stores the validated usage records and returns the number of stored
+// usage records. The data versions of their municipalities are updated by the
+// database
+func Store(ctx context.Context, records []structs.UsageRecord) (int64, error) {
+	return Copy(ctx, db.Pool, records)
+}
+
+// Insert stores a single validated usage record
+func Insert(ctx context.Context, record structs.UsageRecord) error {
+	q, err := db.Queries.Raw("insert-usage")
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Pool.Exec(ctx, q, Row(record)...)
+	return err
+}
+
+// Row converts the validated usage record into the values of the columns
+// filled when storing usage records. The ids are converted into uuids as the
+// binary copy protocol is not able to convert them from plain strings
+func Row(record structs.UsageRecord) []any {
+	var usageType, consumerID *uuid.UUID
+	if record.UsageType != nil {
+		id := uuid.MustParse(*record.UsageType)
+		usageType = &id
+	}
+	if record.ConsumerID != nil {
+		id := uuid.MustParse(*record.ConsumerID)
+		consumerID = &id
+	}
+	return []any{record.Time, record.Amount, usageType, consumerID, *record.ARS}
+}
@@ -0,0 +1,123 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservice/internal"
+	"microservice/internal/db"
+	apiErrors "microservice/internal/errors"
+	"microservice/structs"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+)
+
+var ErrMissingTime = errors.New("the time of the usage is missing")
+
+// Validator checks usage records before they are stored. The existence of
+// each consumer and usage type is only queried once per validator, therefore
+// a validator should not be reused across multiple requests
+type Validator struct {
+	knownConsumers  map[string]bool
+	knownUsageTypes map[string]bool
+}
+
+func NewValidator() *Validator {
+	return &Validator{
+		knownConsumers:  make(map[string]bool),
+		knownUsageTypes: make(map[string]bool),
+	}
+}
+
+// Validate checks the usage records and returns the issues found in the
+// records. Each issue names the index of the usage record it has been found
+// in. The returned error is only set if the validation itself failed
+func (v *Validator) Validate(ctx context.Context, records []structs.UsageRecord) ([]error, error) {
+	var issues []error
+	for index, record := range records {
+		recordIssues, err := v.ValidateRecord(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range recordIssues {
+			issues = append(issues, fmt.Errorf("record %d: %w", index, issue))
+		}
+	}
+	return issues, nil
+}
+
+// ValidateRecord checks a single usage record and returns the issues found in
+// the record. The returned error is only set if the validation itself failed
+func (v *Validator) ValidateRecord(ctx context.Context, record structs.UsageRecord) ([]error, error) {
+	var issues []error
+
+	if !record.Time.Valid {
+		issues = append(issues, ErrMissingTime)
+	}
+
+	ars := ""
+	if record.ARS != nil {
+		ars = *record.ARS
+	}
+	if serviceErr := internal.ValidateARS(ars); serviceErr != nil {
+		// the errors of an invalid ARS name the failed component which is more
+		// helpful than the generic detail
+		if len(serviceErr.Errors) > 0 {
+			issues = append(issues, serviceErr.Errors...)
+		} else {
+			issues = append(issues, errors.New(serviceErr.Detail))
+		}
+	}
+
+	if record.UsageType != nil {
+		if serviceErr := internal.ValidateUsageTypeID(*record.UsageType); serviceErr != nil {
+			issues = append(issues, errors.New(serviceErr.Detail))
+		} else {
+			exists, err := v.exists(ctx, "usage-type-exists", v.knownUsageTypes, *record.UsageType)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				issues = append(issues, errors.New(apiErrors.ErrUnknownUsageType.Detail))
+			}
+		}
+	}
+
+	if record.ConsumerID != nil {
+		if serviceErr := internal.ValidateConsumerID(*record.ConsumerID); serviceErr != nil {
+			issues = append(issues, errors.New(serviceErr.Detail))
+		} else {
+			exists, err := v.exists(ctx, "consumer-exists", v.knownConsumers, *record.ConsumerID)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				issues = append(issues, errors.New(apiErrors.ErrUnknownConsumer.Detail))
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// exists checks if the id exists using the supplied query. The result is
+// cached in the known ids for the lifetime of the validator
+func (v *Validator) exists(ctx context.Context, queryName string, knownIDs map[string]bool, id string) (bool, error) {
+	if exists, isCached := knownIDs[id]; isCached {
+		return exists, nil
+	}
+
+	q, err := db.Queries.Raw(queryName)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = pgxscan.Get(ctx, db.Pool, &exists, q, id)
+	if err != nil {
+		return false, err
+	}
+
+	knownIDs[id] = exists
+	return exists, nil
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"microservice/internal"
+)
+
+// This file contains the metrics exposed by the service. The metrics are
+// served on a separate listener to keep them out of the public api
+
+// Namespace prefixes the names of all metrics exposed by the service
+const Namespace = "usage_history"
+
+// unmatchedRoute is used as route label for requests which did not match any
+// route to keep the cardinality of the labels bounded
+const unmatchedRoute = "unmatched"
+
+// Registry contains every metric exposed by the service. Additional
+// collectors need to be registered before the metrics are served
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RequestDuration records the time needed to answer a request, labeled by
+	// the route template, method and status code of the request
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time needed to answer the http requests",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// QueryDuration records the execution time of the sql queries, labeled by
+	// the name of the query
+	QueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Execution time of the sql queries",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query", "success"})
+
+	// QueryRows counts the rows returned or affected by the sql queries,
+	// labeled by the name of the query
+	QueryRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "db",
+		Name:      "query_rows_total",
+		Help:      "Rows returned or affected by the sql queries",
+	}, []string{"query"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{Namespace: Namespace}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   Namespace,
+			Name:        "info",
+			Help:        "Information about the service",
+			ConstLabels: prometheus.Labels{"service": internal.ServiceName},
+		}, func() float64 { return 1 }),
+		RequestDuration,
+		QueryDuration,
+		QueryRows,
+	)
+}
+
+// Middleware records the RequestDuration of every request. Every histogram
+// also counts the requests, therefore no separate request counter is needed
+func Middleware(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	RequestDuration.
+		WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).
+		Observe(time.Since(start).Seconds())
+}
+
+// NewServer creates the http server exposing the metrics in the Registry on
+// the /metrics path of the address
+func NewServer(address string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+	return &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
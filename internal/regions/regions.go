@@ -0,0 +1,120 @@
+package regions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"microservice/internal/db"
+	"microservice/structs"
+)
+
+var ErrMissingColumn = errors.New("missing column")
+
+// Region is a region of the Gemeindeverzeichnis which is stored in the
+// region registry
+type Region struct {
+	ARS        string
+	Name       string
+	Population *int64
+}
+
+// Parse converts the rows of the table into regions. The header row needs to
+// contain the columns "ars" and "name". The column "population" is optional
+// and may be empty for regions without a known population. The returned error
+// names every invalid row
+func Parse(table [][]string) ([]Region, error) {
+	if len(table) == 0 {
+		return nil, fmt.Errorf("%w: the file has no header row", ErrMissingColumn)
+	}
+
+	columns := map[string]int{"ars": -1, "name": -1, "population": -1}
+	for index, name := range table[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, isKnown := columns[name]; isKnown {
+			columns[name] = index
+		}
+	}
+	for _, required := range []string{"ars", "name"} {
+		if columns[required] == -1 {
+			return nil, fmt.Errorf("%w '%s'", ErrMissingColumn, required)
+		}
+	}
+
+	var regions []Region
+	var issues []error
+	for index, row := range table[1:] {
+		region, err := parseRow(row, columns)
+		if err != nil {
+			// the header row is the first row of the file
+			issues = append(issues, fmt.Errorf("row %d: %w", index+2, err))
+			continue
+		}
+		if region.ARS == "" && region.Name == "" {
+			continue
+		}
+		regions = append(regions, region)
+	}
+	return regions, errors.Join(issues...)
+}
+
+func parseRow(row []string, columns map[string]int) (Region, error) {
+	cell := func(column string) string {
+		index := columns[column]
+		if index == -1 || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+
+	region := Region{ARS: cell("ars"), Name: cell("name")}
+	if region.ARS == "" && region.Name == "" {
+		return region, nil
+	}
+
+	if _, err := structs.ParseARS(region.ARS); err != nil {
+		return region, err
+	}
+	if region.Name == "" {
+		return region, fmt.Errorf("missing name of region '%s'", region.ARS)
+	}
+
+	if rawPopulation := cell("population"); rawPopulation != "" {
+		population, err := strconv.ParseInt(rawPopulation, 10, 64)
+		if err != nil || population < 0 {
+			return region, fmt.Errorf("invalid population '%s'", rawPopulation)
+		}
+		region.Population = &population
+	}
+	return region, nil
+}
+
+// Store upserts the regions into the region registry in a single transaction
+// and records the status of the Gemeindeverzeichnis in the comment of the
+// registry. Regions missing in the Gemeindeverzeichnis are kept
+func Store(ctx context.Context, regions []Region, status time.Time) error {
+	query, err := db.Queries.Raw("upsert-region")
+	if err != nil {
+		return err
+	}
+
+	return pgx.BeginFunc(ctx, db.Pool, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for _, region := range regions {
+			batch.Queue(query, region.ARS, region.Name, region.Population)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+
+		// the comment only contains the formatted date and doesn't need to be
+		// escaped
+		_, err := tx.Exec(ctx, fmt.Sprintf("COMMENT ON TABLE registry.regions IS 'Gemeindeverzeichnis, status %s'", status.Format(time.DateOnly)))
+		return err
+	})
+}
@@ -0,0 +1,57 @@
+package regions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	population := int64(1892122)
+	tests := []struct {
+		name    string
+		table   [][]string
+		regions []Region
+		error   string
+	}{
+		{
+			name: "Valid_Regions",
+			table: [][]string{
+				{"Population", "ARS", "Name"},
+				{"1892122", "020000000000", "Hamburg"},
+				{"", "03", " Niedersachsen "},
+				{"", "", ""},
+			},
+			regions: []Region{
+				{ARS: "020000000000", Name: "Hamburg", Population: &population},
+				{ARS: "03", Name: "Niedersachsen"},
+			},
+		},
+		{
+			name:  "Missing_Column",
+			table: [][]string{{"ars", "population"}},
+			error: "missing column 'name'",
+		},
+		{
+			name: "Invalid_Rows",
+			table: [][]string{
+				{"ars", "name", "population"},
+				{"0300", "Niedersachsen"},
+				{"03", ""},
+				{"03", "Niedersachsen", "many"},
+			},
+			error: "row 3: missing name of region '03'\nrow 4: invalid population 'many'",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			regions, err := Parse(test.table)
+			if test.error != "" {
+				assert.ErrorContains(t, err, test.error)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.regions, regions)
+		})
+	}
+}
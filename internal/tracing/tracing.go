@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"microservice/internal"
+)
+
+// This file contains the configuration of the tracing. The spans are exported
+// using the exporter selected in the OTEL_TRACES_EXPORTER environment
+// variable. The exporters are configured using the environment variables
+// defined by the OpenTelemetry specification, e.g., the
+// OTEL_EXPORTER_OTLP_ENDPOINT
+
+// The supported values of the OTEL_TRACES_EXPORTER environment variable
+const (
+	ExporterOTLP    = "otlp"
+	ExporterConsole = "console"
+	ExporterNone    = "none"
+)
+
+// AttributeRequestID contains the id set by the requestid middleware on the
+// span of a request
+const AttributeRequestID = attribute.Key("http.request.id")
+
+// Configure sets the global tracer provider and propagator. If the
+// OTEL_TRACES_EXPORTER environment variable is not set, the spans are
+// exported using OTLP if an OTLP endpoint has been configured and dropped
+// otherwise. The returned function flushes the remaining spans and needs to
+// be called before the service exits
+func Configure(ctx context.Context) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch selectedExporter() {
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterConsole:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unsupported traces exporter '%s'", selectedExporter())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(internal.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+// selectedExporter reads the exporter from the OTEL_TRACES_EXPORTER
+// environment variable
+func selectedExporter() string {
+	if exporter, isSet := os.LookupEnv("OTEL_TRACES_EXPORTER"); isSet {
+		return exporter
+	}
+	for _, variable := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+		if _, isSet := os.LookupEnv(variable); isSet {
+			return ExporterOTLP
+		}
+	}
+	return ExporterNone
+}
+
+// Middlewares starts a span for every request which continues the trace
+// propagated by the gateway. The span is named after the route template and
+// carries the id set by the requestid middleware, which therefore needs to
+// run before these middlewares
+func Middlewares() []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		otelgin.Middleware(internal.ServiceName),
+		func(c *gin.Context) {
+			span := trace.SpanFromContext(c.Request.Context())
+			span.SetAttributes(AttributeRequestID.String(requestid.Get(c)))
+		},
+	}
+}
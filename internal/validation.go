@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"microservice/structs"
+
+	"github.com/google/uuid"
+	"github.com/wisdom-oss/common-go/v3/types"
+
+	apiErrors "microservice/internal/errors"
+)
+
+// ValidateConsumerID checks if the consumer id is set and is a valid uuid.
+// If the consumer id is not valid, the error describing the issue is returned
+func ValidateConsumerID(consumerID string) *types.ServiceError {
+	if consumerID == "" {
+		return &apiErrors.ErrEmptyConsumerID
+	}
+
+	if err := uuid.Validate(consumerID); err != nil {
+		return &apiErrors.ErrInvalidConsumerID
+	}
+
+	return nil
+}
+
+// ValidateUsageTypeID checks if the usage type id is set and is a valid uuid.
+// If the usage type id is not valid, the error describing the issue is
+// returned
+func ValidateUsageTypeID(usageTypeID string) *types.ServiceError {
+	if usageTypeID == "" {
+		return &apiErrors.ErrEmptyUsageTypeID
+	}
+
+	if err := uuid.Validate(usageTypeID); err != nil {
+		return &apiErrors.ErrInvalidUsageTypeID
+	}
+
+	return nil
+}
+
+// ValidateARS checks if the ARS is set and is a valid municipal ARS. If the
+// ARS is not valid, the error describing the issue is returned
+func ValidateARS(ars string) *types.ServiceError {
+	if serviceErr := ValidateARSPrefix(ars); serviceErr != nil {
+		return serviceErr
+	}
+
+	if !structs.MustParseARS(ars).Municipal() {
+		return invalidARS(structs.ARSError{Value: ars, Reason: "needs to contain 12 digits"})
+	}
+
+	return nil
+}
+
+// ValidateARSPrefix checks if the ARS is set and is a valid ARS of any
+// regional level. If the ARS is not valid, the error describing the issue is
+// returned
+func ValidateARSPrefix(ars string) *types.ServiceError {
+	if ars == "" {
+		return &apiErrors.ErrEmptyARS
+	}
+
+	if _, err := structs.ParseARS(ars); err != nil {
+		return invalidARS(err)
+	}
+
+	return nil
+}
+
+// invalidARS returns a copy of apiErrors.ErrInvalidARS listing the reason why
+// the ARS is invalid
+func invalidARS(reason error) *types.ServiceError {
+	serviceErr := apiErrors.ErrInvalidARS
+	serviceErr.Errors = []error{reason}
+	return &serviceErr
+}
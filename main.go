@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/rs/zerolog/log"
 	healthcheckServer "github.com/wisdom-oss/go-healthcheck/server"
@@ -15,6 +14,8 @@ import (
 	"microservice/internal"
 	"microservice/internal/config"
 	"microservice/internal/db"
+	"microservice/internal/metrics"
+	"microservice/internal/tracing"
 	"microservice/routes"
 	routeUtils "microservice/routes/utils"
 
@@ -24,36 +25,84 @@ import (
 // the main function bootstraps the http server and handlers used for this
 // microservice
 func main() {
+	// run the requested subcommand instead of the http server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "regions":
+			os.Exit(runRegions(os.Args[2:]))
+		}
+	}
+
 	// create a new logger for the main function
 	l := log.Logger
 	l.Info().Msgf("configuring %s service", internal.ServiceName)
 
+	// connect to the database and keep the cache in sync with the usages
+	db.Connect()
+	go db.ListenForUsageChanges(context.Background())
+
+	// configure the export of the spans
+	shutdownTracing, err := tracing.Configure(context.Background())
+	if err != nil {
+		l.Fatal().Err(err).Msg("unable to configure tracing")
+	}
+
 	// create the healthcheck server
 	hcServer := healthcheckServer.HealthcheckServer{}
 	hcServer.InitWithFunc(func() error {
 		// test if the database is reachable
 		return db.Pool.Ping(context.Background())
 	})
-	err := hcServer.Start()
+	err = hcServer.Start()
 	if err != nil {
 		l.Fatal().Err(err).Msg("unable to start healthcheck server")
 	}
 	go hcServer.Run()
 
+	// create the metrics server
+	metricsServer := metrics.NewServer(config.Current.HTTP.MetricsListenAddress)
+	go func() {
+		if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			l.Fatal().Err(err).Msg("An error occurred while starting the metrics server")
+		}
+	}()
+
 	// prepare some scope requirers to make the route definition easiser
 	scopeRequirer := jwt.ScopeRequirer{}
 	scopeRequirer.Configure(internal.ServiceName)
 	r := config.PrepareRouter()
 	r.Use(routeUtils.ReadPageSettings)
+	r.Use(routeUtils.ReadTimeRange)
+	r.Use(routeUtils.ReadOutputSettings)
 	r.GET("/", scopeRequirer.RequireRead, routes.PagedUsages)
 	r.GET("/consumer/*consumerID", scopeRequirer.RequireRead, routes.ConsumerUsages)
 	r.GET("/type/*usageTypeID", scopeRequirer.RequireRead, routes.TypedUsages)
 	r.GET("/municipal/*ars", scopeRequirer.RequireRead, routes.MunicipalUsages)
+	r.GET("/search", scopeRequirer.RequireRead, routes.SearchUsages)
+	r.GET("/aggregate", scopeRequirer.RequireRead, routes.AggregateUsages)
+	r.GET("/municipalities", scopeRequirer.RequireRead, routes.Municipalities)
+	r.GET("/types", scopeRequirer.RequireRead, routes.UsageTypes)
+	r.GET("/types/:usageTypeID", scopeRequirer.RequireRead, routes.UsageType)
+	r.GET("/consumers", scopeRequirer.RequireRead, routes.Consumers)
+	r.GET("/consumers/:consumerID/summary", scopeRequirer.RequireRead, routes.ConsumerSummary)
+	r.POST("/", scopeRequirer.RequireWrite, routes.IngestUsage)
+	r.POST("/batch", scopeRequirer.RequireWrite, routes.IngestUsageBatch)
+	r.POST("/import", scopeRequirer.RequireWrite, routes.ImportUsages)
 
 	// create http server
 	server := &http.Server{
-		Addr:    config.ListenAddress,
-		Handler: r,
+		Addr:              config.Current.HTTP.ListenAddress,
+		Handler:           r,
+		ReadHeaderTimeout: config.Current.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       config.Current.HTTP.ReadTimeout,
+		WriteTimeout:      config.Current.HTTP.WriteTimeout,
+		IdleTimeout:       config.Current.HTTP.IdleTimeout,
 	}
 
 	l.Info().Msg("starting http server")
@@ -73,7 +122,7 @@ func main() {
 	l.Info().Msg("server ready to accept connections")
 	<-shutdownSignal
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.Current.HTTP.ShutdownTimeout)
 	defer cancel()
 
 	err = server.Shutdown(ctx)
@@ -81,4 +130,14 @@ func main() {
 		l.Fatal().Err(err).Msg("An error occurred while shutting down http server")
 	}
 
+	err = metricsServer.Shutdown(ctx)
+	if err != nil {
+		l.Fatal().Err(err).Msg("An error occurred while shutting down metrics server")
+	}
+
+	err = shutdownTracing(ctx)
+	if err != nil {
+		l.Fatal().Err(err).Msg("An error occurred while flushing the spans")
+	}
+
 }
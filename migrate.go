@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"microservice/internal/db"
+)
+
+// runMigrate implements the `migrate` subcommand which manages the schema of
+// the database without starting the http server. The `up` action applies
+// every pending migration, the `down` action reverts the latest migrations
+// (default: one) and the `status` action lists the migrations and the time
+// they have been applied
+func runMigrate(arguments []string) int {
+	l := log.With().Str("command", "migrate").Logger()
+
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: service migrate up | down [steps] | status")
+	}
+	if err := flags.Parse(arguments); err != nil {
+		return 2
+	}
+
+	action := flags.Arg(0)
+	steps := 1
+	switch {
+	case action == "down" && flags.NArg() == 2:
+		var err error
+		steps, err = strconv.Atoi(flags.Arg(1))
+		if err != nil || steps < 1 {
+			flags.Usage()
+			return 2
+		}
+	case (action == "up" || action == "down" || action == "status") && flags.NArg() == 1:
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	db.Open()
+	ctx := context.Background()
+
+	switch action {
+	case "up":
+		migrations, err := db.MigrateUp(ctx)
+		printMigrations("applied", migrations)
+		if err != nil {
+			l.Error().Err(err).Msg("unable to apply migrations")
+			return 1
+		}
+	case "down":
+		migrations, err := db.MigrateDown(ctx, steps)
+		printMigrations("reverted", migrations)
+		if err != nil {
+			l.Error().Err(err).Msg("unable to revert migrations")
+			return 1
+		}
+	case "status":
+		statuses, err := db.MigrationStatuses(ctx)
+		if err != nil {
+			l.Error().Err(err).Msg("unable to read migrations")
+			return 1
+		}
+		printStatuses(statuses)
+	}
+	return 0
+}
+
+// printMigrations writes the changed migrations to stdout
+func printMigrations(change string, migrations []db.Migration) {
+	if len(migrations) == 0 {
+		fmt.Printf("no migrations %s\n", change)
+	}
+	for _, migration := range migrations {
+		fmt.Printf("%s %04d_%s\n", change, migration.Version, migration.Name)
+	}
+}
+
+// printStatuses writes the migrations as table to stdout
+func printStatuses(statuses []db.MigrationStatus) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Up == "" {
+			appliedAt += " (unknown to this version)"
+		}
+		fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	_ = writer.Flush()
+}
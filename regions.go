@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+
+	"microservice/internal/db"
+	"microservice/internal/ingest"
+	"microservice/internal/regions"
+)
+
+// runRegions implements the `regions` subcommand which loads the regions of a
+// Gemeindeverzeichnis of the Federal Statistical Office into the region
+// registry. The file is a CSV or XLSX file with the columns "ars", "name" and
+// "population" and needs to be versioned by the status (Gebietsstand) of the
+// Gemeindeverzeichnis, which is recorded in the registry. The migrations only
+// seed the Länder and a few regions, so the full Gemeindeverzeichnis needs to
+// be loaded using this subcommand
+func runRegions(arguments []string) int {
+	l := log.With().Str("command", "regions").Logger()
+
+	flags := flag.NewFlagSet("regions", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: service regions [options] -status <date> <file>")
+		flags.PrintDefaults()
+	}
+	rawStatus := flags.String("status", "", "status (Gebietsstand) of the Gemeindeverzeichnis, e.g., 2024-12-31")
+	sheet := flags.String("sheet", "", "sheet of a XLSX file containing the regions (default: first sheet)")
+	delimiter := flags.String("delimiter", ";", "delimiter used in a CSV file")
+	dryRun := flags.Bool("dryRun", false, "only validate the file without storing the regions")
+
+	if err := flags.Parse(arguments); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *rawStatus == "" {
+		flags.Usage()
+		return 2
+	}
+	status, err := time.Parse(time.DateOnly, *rawStatus)
+	if err != nil {
+		l.Error().Str("status", *rawStatus).Msg("the status needs to be a date, e.g., 2024-12-31")
+		return 2
+	}
+	if utf8.RuneCountInString(*delimiter) != 1 {
+		l.Error().Msg("the delimiter needs to be a single character")
+		return 2
+	}
+
+	filename := flags.Arg(0)
+	format, err := ingest.FileFormat(filename)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to load file")
+		return 2
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to open file")
+		return 1
+	}
+	defer file.Close()
+
+	delimiterRune, _ := utf8.DecodeRuneInString(*delimiter)
+	table, err := ingest.ReadTable(file, ingest.Options{Format: format, Delimiter: delimiterRune, Sheet: *sheet})
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to read file")
+		return 1
+	}
+
+	loadedRegions, err := regions.Parse(table)
+	if err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("the file contains invalid regions")
+		return 1
+	}
+	if *dryRun {
+		l.Info().Int("regions", len(loadedRegions)).Msg("the file is valid")
+		return 0
+	}
+
+	db.Connect()
+
+	if err := regions.Store(context.Background(), loadedRegions, status); err != nil {
+		l.Error().Err(err).Str("file", filename).Msg("unable to store regions")
+		return 1
+	}
+	l.Info().Int("regions", len(loadedRegions)).Str("status", *rawStatus).Msg("loaded regions")
+	return 0
+}
@@ -4,3 +4,10 @@ import "embed"
 
 //go:embed *.sql
 var QueryFiles embed.FS
+
+// MigrationFiles contains the versioned migrations creating the schema used
+// by the service. Each version consists of an up and a down migration named
+// <version>_<name>.up.sql and <version>_<name>.down.sql
+//
+//go:embed migrations/*.sql
+var MigrationFiles embed.FS
@@ -0,0 +1,3 @@
+-- The consumers are managed by the consumer service of the platform and are
+-- kept when reverting the migration, even if the table has been created by it
+SELECT 1;
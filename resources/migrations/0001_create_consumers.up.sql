@@ -0,0 +1,8 @@
+-- The consumers are managed by the consumer service of the platform. The
+-- table is only created if the service runs on a database without it
+CREATE SCHEMA IF NOT EXISTS consumers;
+
+CREATE TABLE IF NOT EXISTS consumers.consumers (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name text NOT NULL
+);
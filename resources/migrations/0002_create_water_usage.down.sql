@@ -0,0 +1,3 @@
+-- The usages are shared with the other services of the platform and are kept
+-- when reverting the migration, even if the table has been created by it
+SELECT 1;
@@ -0,0 +1,31 @@
+CREATE SCHEMA IF NOT EXISTS timeseries;
+
+-- the usages are shared with the other services of the platform. If the table
+-- is created by this migration and TimescaleDB has been installed in the
+-- database, the usages are stored in a hypertable. The extension itself is not
+-- installed as it needs to be preloaded by the database server.
+-- An existing table is left unchanged. It may be converted into a hypertable
+-- by hand before applying the later migrations, as they depend on the kind of
+-- the table:
+--
+--   SELECT create_hypertable('timeseries.water_usage', 'time', migrate_data => TRUE);
+--
+-- The conversion locks the table while moving the usages into chunks and can't
+-- be reverted by the migrations
+DO $$
+BEGIN
+    IF to_regclass('timeseries.water_usage') IS NULL THEN
+        CREATE TABLE timeseries.water_usage (
+            time timestamptz NOT NULL,
+            amount double precision NOT NULL,
+            usage_type uuid,
+            consumer uuid,
+            municipality text
+        );
+
+        IF EXISTS (SELECT FROM pg_extension WHERE extname = 'timescaledb') THEN
+            PERFORM create_hypertable('timeseries.water_usage', 'time');
+        END IF;
+    END IF;
+END;
+$$;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS registry.usage_versions;
+
+DROP TABLE IF EXISTS registry.usage_types;
+
+DROP TABLE IF EXISTS registry.regions;
+
+DROP SCHEMA IF EXISTS registry;
@@ -0,0 +1,20 @@
+CREATE SCHEMA IF NOT EXISTS registry;
+
+CREATE TABLE IF NOT EXISTS registry.regions (
+    ars text PRIMARY KEY,
+    name text NOT NULL,
+    population integer
+);
+
+CREATE TABLE IF NOT EXISTS registry.usage_types (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+    description text,
+    external_code text UNIQUE,
+    parent uuid REFERENCES registry.usage_types (id)
+);
+
+CREATE TABLE IF NOT EXISTS registry.usage_versions (
+    municipality text PRIMARY KEY,
+    modified_at timestamptz NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS notify_usage_change ON timeseries.water_usage;
+
+DROP FUNCTION IF EXISTS registry.notify_usage_change();
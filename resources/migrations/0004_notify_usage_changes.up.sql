@@ -0,0 +1,15 @@
+-- notifies the service about changed usages to invalidate its query cache
+CREATE OR REPLACE FUNCTION registry.notify_usage_change()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    PERFORM pg_notify('water_usage_changed', TG_OP);
+    RETURN NULL;
+END;
+$$;
+
+CREATE OR REPLACE TRIGGER notify_usage_change
+AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON timeseries.water_usage
+FOR EACH STATEMENT
+EXECUTE FUNCTION registry.notify_usage_change();
@@ -0,0 +1,5 @@
+-- the index of a plain table has already been dropped concurrently by
+-- reverting the next migration
+DROP INDEX IF EXISTS timeseries.water_usage_order_idx;
+
+DROP FUNCTION IF EXISTS registry.is_hypertable(text, text);
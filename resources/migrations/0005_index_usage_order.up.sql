@@ -0,0 +1,45 @@
+-- reports if the table is a hypertable of TimescaleDB. The information views
+-- of TimescaleDB are queried dynamically as they only exist if the extension
+-- has been installed
+CREATE OR REPLACE FUNCTION registry.is_hypertable(table_schema text, table_name text)
+RETURNS boolean
+LANGUAGE plpgsql
+STABLE
+AS $$
+DECLARE
+    is_hypertable boolean := FALSE;
+BEGIN
+    IF to_regclass('timescaledb_information.hypertables') IS NOT NULL THEN
+        EXECUTE $query$
+            SELECT
+                EXISTS (
+                    SELECT
+                    FROM
+                        timescaledb_information.hypertables
+                    WHERE
+                        hypertable_schema = $1
+                        AND hypertable_name = $2
+                )
+        $query$ INTO is_hypertable USING table_schema, table_name;
+    END IF;
+    RETURN is_hypertable;
+END;
+$$;
+
+-- matches the default sort order of the usage records, including the
+-- coalesced nullable columns, to allow reading the pages from the index.
+-- TimescaleDB does not support creating indexes concurrently on hypertables,
+-- therefore the index is created while blocking writes to the usages. The
+-- index of a plain table is created concurrently by the next migration
+DO $$
+BEGIN
+    IF registry.is_hypertable('timeseries', 'water_usage') THEN
+        CREATE INDEX IF NOT EXISTS water_usage_order_idx ON timeseries.water_usage (
+            time,
+            coalesce(consumer, '00000000-0000-0000-0000-000000000000'::uuid),
+            coalesce(usage_type, '00000000-0000-0000-0000-000000000000'::uuid),
+            coalesce(municipality, '')
+        );
+    END IF;
+END;
+$$;
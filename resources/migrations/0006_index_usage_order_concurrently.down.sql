@@ -0,0 +1,3 @@
+-- migrate: no-transaction
+-- migrate: if SELECT NOT registry.is_hypertable('timeseries', 'water_usage')
+DROP INDEX CONCURRENTLY IF EXISTS timeseries.water_usage_order_idx;
@@ -0,0 +1,11 @@
+-- migrate: no-transaction
+-- migrate: if SELECT NOT registry.is_hypertable('timeseries', 'water_usage')
+-- creates the index of the previous migration concurrently to keep the usages
+-- writable. If the creation fails, the invalid index needs to be dropped
+-- before retrying the migration
+CREATE INDEX CONCURRENTLY IF NOT EXISTS water_usage_order_idx ON timeseries.water_usage (
+    time,
+    coalesce(consumer, '00000000-0000-0000-0000-000000000000'::uuid),
+    coalesce(usage_type, '00000000-0000-0000-0000-000000000000'::uuid),
+    coalesce(municipality, '')
+);
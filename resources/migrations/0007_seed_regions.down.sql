@@ -0,0 +1,51 @@
+-- Only the seeded regions are removed. Other regions loaded from a
+-- Gemeindeverzeichnis using the regions command are kept
+DELETE FROM registry.regions
+WHERE
+    ars IN (
+        '01',
+        '02',
+        '020000000000',
+        '03',
+        '031',
+        '03151',
+        '032',
+        '033',
+        '034',
+        '03401',
+        '034010000000',
+        '03402',
+        '034020000000',
+        '03403',
+        '034030000000',
+        '03404',
+        '034040000000',
+        '03405',
+        '034050000000',
+        '03451',
+        '03452',
+        '03453',
+        '03454',
+        '03455',
+        '03456',
+        '03457',
+        '03458',
+        '03459',
+        '03460',
+        '03461',
+        '03462',
+        '04',
+        '05',
+        '06',
+        '07',
+        '08',
+        '09',
+        '10',
+        '11',
+        '110000000000',
+        '12',
+        '13',
+        '14',
+        '15',
+        '16'
+    );
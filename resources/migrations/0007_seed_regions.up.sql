@@ -0,0 +1,58 @@
+-- The regions are identified by the ARS prefix of their regional level. The
+-- population is taken from the Gemeindeverzeichnis of the Federal Statistical
+-- Office (status 31.12.2022). It has only been added for the Länder and the
+-- municipalities of the city states so far. The seed only contains the Länder
+-- and the regions needed by the tests. The full Gemeindeverzeichnis is loaded
+-- from a file using `service regions -status <date> <file>`
+INSERT INTO
+    registry.regions (ars, name, population)
+VALUES
+    ('01', 'Schleswig-Holstein', 2953270),
+    ('02', 'Hamburg', 1892122),
+    ('020000000000', 'Hamburg', 1892122),
+    ('03', 'Niedersachsen', 8140242),
+    ('031', 'Braunschweig', NULL),
+    ('03151', 'Gifhorn', NULL),
+    ('032', 'Hannover', NULL),
+    ('033', 'Lüneburg', NULL),
+    ('034', 'Weser-Ems', NULL),
+    ('03401', 'Delmenhorst', NULL),
+    ('034010000000', 'Delmenhorst', NULL),
+    ('03402', 'Emden', NULL),
+    ('034020000000', 'Emden', NULL),
+    ('03403', 'Oldenburg (Oldb)', NULL),
+    ('034030000000', 'Oldenburg (Oldb)', NULL),
+    ('03404', 'Osnabrück', NULL),
+    ('034040000000', 'Osnabrück', NULL),
+    ('03405', 'Wilhelmshaven', NULL),
+    ('034050000000', 'Wilhelmshaven', NULL),
+    ('03451', 'Ammerland', NULL),
+    ('03452', 'Aurich', NULL),
+    ('03453', 'Cloppenburg', NULL),
+    ('03454', 'Emsland', NULL),
+    ('03455', 'Friesland', NULL),
+    ('03456', 'Grafschaft Bentheim', NULL),
+    ('03457', 'Leer', NULL),
+    ('03458', 'Oldenburg', NULL),
+    ('03459', 'Osnabrück', NULL),
+    ('03460', 'Vechta', NULL),
+    ('03461', 'Wesermarsch', NULL),
+    ('03462', 'Wittmund', NULL),
+    ('04', 'Bremen', 684864),
+    ('05', 'Nordrhein-Westfalen', 18139116),
+    ('06', 'Hessen', 6391360),
+    ('07', 'Rheinland-Pfalz', 4159150),
+    ('08', 'Baden-Württemberg', 11280257),
+    ('09', 'Bayern', 13369393),
+    ('10', 'Saarland', 992666),
+    ('11', 'Berlin', 3755251),
+    ('110000000000', 'Berlin', 3755251),
+    ('12', 'Brandenburg', 2573135),
+    ('13', 'Mecklenburg-Vorpommern', 1628378),
+    ('14', 'Sachsen', 4086152),
+    ('15', 'Sachsen-Anhalt', 2186643),
+    ('16', 'Thüringen', 2126846)
+ON CONFLICT (ars) DO UPDATE
+SET
+    name = excluded.name,
+    population = excluded.population;
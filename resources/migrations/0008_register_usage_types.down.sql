@@ -0,0 +1,14 @@
+-- removes the registered usage types which have not been maintained since
+DELETE FROM registry.usage_types
+WHERE
+    name = id::text
+    AND description IS NULL
+    AND external_code IS NULL
+    AND parent IS NULL
+    AND NOT EXISTS (
+        SELECT
+        FROM
+            registry.usage_types child
+        WHERE
+            child.parent = usage_types.id
+    );
@@ -0,0 +1,15 @@
+-- registers the usage types which have been recorded before the catalogue
+-- existed, so every recorded usage type is contained in it. Their name
+-- defaults to their id until it has been maintained in the catalogue. Usage
+-- types recorded afterwards need to be added to the catalogue before usages
+-- using them are ingested
+INSERT INTO
+    registry.usage_types (id, name)
+SELECT DISTINCT
+    usage_type,
+    usage_type::text
+FROM
+    timeseries.water_usage
+WHERE
+    usage_type IS NOT NULL
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS version_usage_truncate ON timeseries.water_usage;
+
+DROP TRIGGER IF EXISTS version_usage_delete ON timeseries.water_usage;
+
+DROP TRIGGER IF EXISTS version_usage_update ON timeseries.water_usage;
+
+DROP TRIGGER IF EXISTS version_usage_insert ON timeseries.water_usage;
+
+DROP TRIGGER IF EXISTS version_usage_change ON timeseries.water_usage;
+
+DROP FUNCTION IF EXISTS registry.version_all_usages();
+
+DROP FUNCTION IF EXISTS registry.version_changed_usages();
@@ -0,0 +1,127 @@
+-- updates the data versions of the municipalities of the usages changed by a
+-- statement, regardless of the client writing them. Each municipality is
+-- updated once per statement and in the order of their ARS, so concurrent
+-- writers lock the versions in the same order. Usages without a municipality
+-- are versioned using an empty municipality
+CREATE OR REPLACE FUNCTION registry.version_changed_usages()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO
+            registry.usage_versions (municipality, modified_at)
+        SELECT
+            changed.municipality,
+            clock_timestamp()
+        FROM
+            (
+                SELECT DISTINCT
+                    coalesce(municipality, '') AS municipality
+                FROM
+                    new_usages
+            ) changed
+        ORDER BY
+            changed.municipality
+        ON CONFLICT (municipality) DO UPDATE
+        SET
+            modified_at = excluded.modified_at;
+    ELSIF TG_OP = 'UPDATE' THEN
+        INSERT INTO
+            registry.usage_versions (municipality, modified_at)
+        SELECT
+            changed.municipality,
+            clock_timestamp()
+        FROM
+            (
+                SELECT
+                    coalesce(municipality, '') AS municipality
+                FROM
+                    old_usages
+                UNION
+                SELECT
+                    coalesce(municipality, '') AS municipality
+                FROM
+                    new_usages
+            ) changed
+        ORDER BY
+            changed.municipality
+        ON CONFLICT (municipality) DO UPDATE
+        SET
+            modified_at = excluded.modified_at;
+    ELSE
+        INSERT INTO
+            registry.usage_versions (municipality, modified_at)
+        SELECT
+            changed.municipality,
+            clock_timestamp()
+        FROM
+            (
+                SELECT DISTINCT
+                    coalesce(municipality, '') AS municipality
+                FROM
+                    old_usages
+            ) changed
+        ORDER BY
+            changed.municipality
+        ON CONFLICT (municipality) DO UPDATE
+        SET
+            modified_at = excluded.modified_at;
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
+-- updates the wildcard version, which is matched by every region, if the
+-- changed municipalities are unknown
+CREATE OR REPLACE FUNCTION registry.version_all_usages()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    INSERT INTO
+        registry.usage_versions (municipality, modified_at)
+    VALUES
+        ('*', clock_timestamp())
+    ON CONFLICT (municipality) DO UPDATE
+    SET
+        modified_at = excluded.modified_at;
+    RETURN NULL;
+END;
+$$;
+
+-- TimescaleDB does not support transition tables on hypertables. Therefore,
+-- every change of the usages in a hypertable updates the wildcard version
+DO $$
+BEGIN
+    IF registry.is_hypertable('timeseries', 'water_usage') THEN
+        CREATE OR REPLACE TRIGGER version_usage_change
+        AFTER INSERT OR UPDATE OR DELETE ON timeseries.water_usage
+        FOR EACH STATEMENT
+        EXECUTE FUNCTION registry.version_all_usages();
+    ELSE
+        CREATE OR REPLACE TRIGGER version_usage_insert
+        AFTER INSERT ON timeseries.water_usage
+        REFERENCING NEW TABLE AS new_usages
+        FOR EACH STATEMENT
+        EXECUTE FUNCTION registry.version_changed_usages();
+
+        CREATE OR REPLACE TRIGGER version_usage_update
+        AFTER UPDATE ON timeseries.water_usage
+        REFERENCING OLD TABLE AS old_usages NEW TABLE AS new_usages
+        FOR EACH STATEMENT
+        EXECUTE FUNCTION registry.version_changed_usages();
+
+        CREATE OR REPLACE TRIGGER version_usage_delete
+        AFTER DELETE ON timeseries.water_usage
+        REFERENCING OLD TABLE AS old_usages
+        FOR EACH STATEMENT
+        EXECUTE FUNCTION registry.version_changed_usages();
+    END IF;
+END;
+$$;
+
+CREATE OR REPLACE TRIGGER version_usage_truncate
+AFTER TRUNCATE ON timeseries.water_usage
+FOR EACH STATEMENT
+EXECUTE FUNCTION registry.version_all_usages();
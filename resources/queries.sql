@@ -1,57 +1,217 @@
--- name: get-paginated
+-- name: consumer-exists
+SELECT
+    EXISTS (
+        SELECT
+            id
+        FROM
+            consumers.consumers
+        WHERE
+            id = $1
+    );
+
+-- name: insert-usage
+INSERT INTO
+    timeseries.water_usage (time, amount, usage_type, consumer, municipality)
+VALUES
+    ($1, $2, $3, $4, $5);
+
+-- name: create-migration-table
+CREATE TABLE IF NOT EXISTS public.usage_history_migrations (
+    version integer PRIMARY KEY,
+    name text NOT NULL,
+    applied_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- name: lock-migrations
+SELECT
+    pg_advisory_lock(hashtext('usage-history-migrations'));
+
+-- name: unlock-migrations
+SELECT
+    pg_advisory_unlock(hashtext('usage-history-migrations'));
+
+-- name: get-applied-migrations
 SELECT
-    *
+    version,
+    name,
+    applied_at
 FROM
-    timeseries.water_usage
-LIMIT
-    $1
-OFFSET
-    $2;
+    public.usage_history_migrations
+ORDER BY
+    version;
 
--- name: consumer-exists
+-- name: insert-migration
+INSERT INTO
+    public.usage_history_migrations (version, name)
+VALUES
+    ($1, $2);
+
+-- name: delete-migration
+DELETE FROM public.usage_history_migrations
+WHERE
+    version = $1;
+
+-- name: get-municipalities
+SELECT
+    municipality.ars,
+    municipality.name,
+    municipality.population,
+    coalesce(
+        (
+            SELECT
+                json_agg(
+                    json_build_object('ars', parent.ars, 'name', parent.name)
+                    ORDER BY
+                        length(parent.ars)
+                )
+            FROM
+                registry.regions parent
+            WHERE
+                length(parent.ars) < length(municipality.ars)
+                AND starts_with(municipality.ars, parent.ars)
+        ),
+        '[]'
+    ) AS parents
+FROM
+    registry.regions municipality
+WHERE
+    length(municipality.ars) = 12
+ORDER BY
+    municipality.ars;
+
+-- name: usage-type-exists
 SELECT
     EXISTS (
         SELECT
             id
         FROM
-            consumers.consumers
+            registry.usage_types
         WHERE
             id = $1
     );
 
--- name: consumer-usages
+-- name: get-usage-types
 SELECT
-    *
+    id,
+    name,
+    description,
+    external_code,
+    parent
 FROM
-    timeseries.water_usage
+    registry.usage_types
+ORDER BY
+    name;
+
+-- name: get-usage-type
+SELECT
+    id,
+    name,
+    description,
+    external_code,
+    parent
+FROM
+    registry.usage_types
 WHERE
-    consumer = $1
-LIMIT
-    $2
-OFFSET
-    $3;
+    id = $1;
 
--- name: municipal-usages
+-- name: search-consumers
 SELECT
-    *
+    id,
+    name
 FROM
-    timeseries.water_usage
+    consumers.consumers
 WHERE
-    municipality = $1
+    strpos(lower(name), lower($1)) > 0
+ORDER BY
+    name,
+    id
 LIMIT
     $2
 OFFSET
     $3;
 
+-- name: count-consumers
+SELECT
+    count(*)
+FROM
+    consumers.consumers
+WHERE
+    strpos(lower(name), lower($1)) > 0;
 
--- name: typed-usages
+-- name: consumer-summary
 SELECT
-    *
+    min(time) AS first_reading,
+    max(time) AS last_reading,
+    coalesce(sum(amount), 0) AS total_amount,
+    count(*) AS readings,
+    (
+        SELECT
+            usage_type
+        FROM
+            timeseries.water_usage
+        WHERE
+            consumer = $1
+            AND usage_type IS NOT NULL
+        GROUP BY
+            usage_type
+        ORDER BY
+            sum(amount) DESC,
+            count(*) DESC
+        LIMIT
+            1
+    ) AS dominant_usage_type,
+    coalesce(
+        array_agg(DISTINCT municipality ORDER BY municipality) FILTER (
+            WHERE
+                municipality IS NOT NULL
+        ),
+        '{}'
+    ) AS municipalities
 FROM
     timeseries.water_usage
 WHERE
-    usage_type = $1
-LIMIT
-    $2
-OFFSET
-    $3;
\ No newline at end of file
+    consumer = $1;
+
+-- name: get-usage-version
+SELECT
+    max(modified_at)
+FROM
+    registry.usage_versions
+WHERE
+    coalesce(cardinality($1::text[]), 0) = 0
+    OR municipality = '*'
+    OR EXISTS (
+        SELECT
+        FROM
+            unnest($1::text[]) AS region
+        WHERE
+            starts_with(municipality, region)
+    );
+
+-- name: find-recorded-usages
+SELECT
+    candidate.index - 1
+FROM
+    unnest($1::timestamptz[], $2::uuid[], $3::uuid[], $4::text[])
+    WITH ORDINALITY AS candidate (time, consumer, usage_type, municipality, index)
+WHERE
+    EXISTS (
+        SELECT
+        FROM
+            timeseries.water_usage
+        WHERE
+            water_usage.time = candidate.time
+            AND water_usage.consumer IS NOT DISTINCT FROM candidate.consumer
+            AND water_usage.usage_type IS NOT DISTINCT FROM candidate.usage_type
+            AND water_usage.municipality IS NOT DISTINCT FROM candidate.municipality
+    );
+
+-- name: upsert-region
+INSERT INTO
+    registry.regions (ars, name, population)
+VALUES
+    ($1, $2, $3)
+ON CONFLICT (ars) DO UPDATE
+SET
+    name = excluded.name,
+    population = excluded.population;
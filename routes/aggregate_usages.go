@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"errors"
+	"microservice/internal/db"
+	apiErrors "microservice/internal/errors"
+	"microservice/structs"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+func AggregateUsages(c *gin.Context) {
+	var settings structs.AggregationSettings
+	if err := c.ShouldBindQuery(&settings); err != nil {
+		c.Abort()
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) && validationErrors[0].Field() == "TimeZone" {
+			apiErrors.ErrInvalidTimeZone.Emit(c)
+			return
+		}
+		apiErrors.ErrInvalidAggregationBucket.Emit(c)
+		return
+	}
+
+	var query db.UsageQuery
+	if !applyUsageFilter(c, &query) {
+		return
+	}
+
+	if answerConditional(c) {
+		return
+	}
+
+	q, args := query.BuildAggregation(settings.Bucket, settings.TimeZone)
+
+	var aggregations []structs.AggregatedUsage
+	err := db.CachedSelect(c, &aggregations, q, args...)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	sendJSON(c, aggregations, usageMeta(structs.EnvelopeMeta{}), nil)
+}
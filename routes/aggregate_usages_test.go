@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservice/internal/db"
+	apiErrors "microservice/internal/errors"
+	"microservice/internal/ingest"
+	"microservice/structs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func _aggregate_usages(t *testing.T) {
+	t.Run("Invalid_Bucket", _au_invalid_bucket)
+	t.Run("Defaults", _au_defaults)
+	t.Run("Yearly_Municipal", _au_yearly_municipal)
+	t.Run("Invalid_Time_Zone", _au_invalid_time_zone)
+	t.Run("Local_Midnight", _au_local_midnight)
+}
+
+func _au_invalid_bucket(t *testing.T) {
+	apiPath := "aggregate"
+	query := "bucket=decade"
+	expectedError := apiErrors.ErrInvalidAggregationBucket
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _au_defaults(t *testing.T) {
+	apiPath := "aggregate"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s", routePrefix, apiPath), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+}
+
+func _au_yearly_municipal(t *testing.T) {
+	apiPath := "aggregate"
+	query := "bucket=year&ars=031515401020"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	var aggregations []structs.AggregatedUsage
+	err = json.NewDecoder(res.Body).Decode(&aggregations)
+	assert.NoError(t, err)
+	for _, aggregation := range aggregations {
+		assert.Equal(t, 1, aggregation.Bucket.Time.In(berlin).YearDay())
+		assert.LessOrEqual(t, aggregation.Minimum, aggregation.Maximum)
+	}
+}
+
+func _au_invalid_time_zone(t *testing.T) {
+	apiPath := "aggregate"
+	query := "tz=Europe/Hanover"
+	expectedError := apiErrors.ErrInvalidTimeZone
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _au_local_midnight(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	midnight := time.Date(2024, time.January, 1, 0, 0, 0, 0, berlin)
+	ars := "031515401020"
+
+	// the reading is stored at midnight in Germany, which is still in the
+	// previous year in UTC
+	err := ingest.Insert(context.Background(), structs.UsageRecord{Time: pgtype.Timestamptz{Time: midnight, Valid: true}, Amount: 12.5, ARS: &ars})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() {
+		_, _ = db.Pool.Exec(context.Background(),
+			"DELETE FROM timeseries.water_usage WHERE time = $1 AND municipality = $2 AND amount = 12.5 AND consumer IS NULL",
+			midnight, ars)
+	})
+
+	tests := []struct {
+		timeZone string
+		bucket   time.Time
+	}{
+		{"", midnight},
+		{"Europe/Berlin", midnight},
+		{"UTC", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		parameters := url.Values{}
+		parameters.Set("bucket", "year")
+		parameters.Set("ars", ars)
+		parameters.Set("from", midnight.Format(time.RFC3339))
+		parameters.Set("until", midnight.Add(time.Second).Format(time.RFC3339))
+		if test.timeZone != "" {
+			parameters.Set("tz", test.timeZone)
+		}
+
+		req := httptest.NewRequest("GET", fmt.Sprintf("%s/aggregate?%s", routePrefix, parameters.Encode()), nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+		if err != nil {
+			t.Fail()
+			t.Log(err)
+		}
+
+		var aggregations []structs.AggregatedUsage
+		err = json.NewDecoder(res.Body).Decode(&aggregations)
+		assert.NoError(t, err)
+		if assert.Len(t, aggregations, 1, test.timeZone) {
+			assert.True(t, test.bucket.Equal(aggregations[0].Bucket.Time), test.timeZone)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"microservice/internal/db"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CacheControl is sent with every response supporting conditional requests.
+// The responses contain protected data and need to be revalidated before they
+// are reused, which is cheap as the revalidation does not query the usages
+const CacheControl = "private, no-cache"
+
+// answerConditional sets the ETag, Last-Modified and Cache-Control headers of
+// a response containing usage data. The ETag is derived from the request and
+// the data version of the requested municipalities, which allows answering
+// matching conditional requests with 304 Not Modified without querying the
+// usages. The data version is added to the request context, so the query
+// results are only read from the db.Cache if they belong to the same data
+// version. If the request has been answered, true is returned
+func answerConditional(c *gin.Context) bool {
+	q, err := db.Queries.Raw("get-usage-version")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return true
+	}
+
+	var version pgtype.Timestamptz
+	err = pgxscan.Get(c, db.Pool, &version, q, requestFilters(c)["ars"])
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return true
+	}
+
+	// the cached query results need to match the data version of the ETag
+	c.Request = c.Request.WithContext(db.WithDataVersion(c.Request.Context(), version))
+
+	etag := requestETag(c, version)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", CacheControl)
+	if version.Valid {
+		c.Header("Last-Modified", version.Time.UTC().Format(http.TimeFormat))
+	}
+
+	if !notModified(c.Request, etag, version) {
+		return false
+	}
+
+	c.Abort()
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// requestETag derives the entity tag of the response from the path, query
+// and accepted media types of the request combined with the data version.
+// The query parameters are sorted by their name to generate the same entity
+// tag regardless of their order.
+// Enveloped responses contain their generation time and are therefore only
+// semantically equivalent, which is why they receive a weak entity tag
+func requestETag(c *gin.Context, version pgtype.Timestamptz) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.Request.URL.Query().Encode()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(c.GetHeader("Accept")))
+	hash.Write([]byte{0})
+	if version.Valid {
+		hash.Write([]byte(version.Time.UTC().Format(time.RFC3339Nano)))
+	}
+
+	etag := `"` + base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:18]) + `"`
+	if c.GetBool(KeyEnvelope) {
+		etag = "W/" + etag
+	}
+	return etag
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since headers of the
+// request as described in RFC 9110. The If-Modified-Since header is only
+// evaluated if the request does not contain the If-None-Match header
+func notModified(r *http.Request, etag string, version pgtype.Timestamptz) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || !version.Valid {
+		return false
+	}
+	return !version.Time.Truncate(time.Second).After(ifModifiedSince)
+}
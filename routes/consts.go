@@ -1,6 +1,30 @@
 package routes
 
 const (
-	KeyPageOffset = "query.offset"
-	KeyPageSize   = "query.page-size"
+	KeyPageOffset     = "query.offset"
+	KeyPageSize       = "query.page-size"
+	KeyPageCursor     = "query.cursor"
+	KeyPageCount      = "query.count"
+	KeyTimeRangeFrom  = "query.from"
+	KeyTimeRangeUntil = "query.until"
+
+	KeyOutputFormat        = "output.format"
+	KeyCSVDelimiter        = "output.csv.delimiter"
+	KeyCSVDecimalSeparator = "output.csv.decimal-separator"
+	KeyFields              = "output.fields"
+	KeyExpandMunicipality  = "output.expand.municipality"
+	KeyEnvelope            = "output.envelope"
+)
+
+const (
+	// HeaderNextCursor contains the cursor pointing to the next page of a
+	// paged response
+	HeaderNextCursor = "X-Next-Cursor"
+
+	// HeaderTotalCount contains the number of usage records matching the
+	// request if the count has been requested
+	HeaderTotalCount = "X-Total-Count"
 )
+
+// UsageUnit is the unit of the recorded usage amounts
+const UsageUnit = "m³"
@@ -1,28 +1,21 @@
 package routes
 
 import (
+	"microservice/internal"
 	"microservice/internal/db"
 	apiErrors "microservice/internal/errors"
-	"microservice/structs"
 	"strings"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 func ConsumerUsages(c *gin.Context) {
 	consumerID := strings.ReplaceAll(strings.TrimSpace(c.Param("consumerID")), "/", "")
 
-	if consumerID == "" {
+	if serviceErr := internal.ValidateConsumerID(consumerID); serviceErr != nil {
 		c.Abort()
-		apiErrors.ErrEmptyConsumerID.Emit(c)
-		return
-	}
-
-	if err := uuid.Validate(consumerID); err != nil {
-		c.Abort()
-		apiErrors.ErrInvalidConsumerID.Emit(c)
+		serviceErr.Emit(c)
 		return
 	}
 
@@ -46,20 +39,15 @@ func ConsumerUsages(c *gin.Context) {
 		return
 	}
 
-	q, err = db.Queries.Raw("consumer-usages")
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
+	var query db.UsageQuery
+	query.Where(db.ColumnConsumer, consumerID)
+	applyTimeRange(c, &query)
+	if !applySort(c, &query) {
 		return
 	}
-	var records []structs.UsageRecord
-	err = pgxscan.Select(c, db.Pool, &records, q, consumerID, c.GetInt(KeyPageSize), c.GetInt(KeyPageOffset))
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
+	if !applyPagination(c, &query) {
 		return
 	}
 
-	c.JSON(200, records)
-
+	sendUsages(c, query, "usages-consumer-"+consumerID)
 }
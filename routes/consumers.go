@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"microservice/internal"
+	"microservice/internal/db"
+	apiErrors "microservice/internal/errors"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"strconv"
+	"strings"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/gin-gonic/gin"
+)
+
+func Consumers(c *gin.Context) {
+	// consumers are only paginated using page numbers as the cursor points to
+	// a usage record
+	if _, usesCursor := c.Get(KeyPageCursor); usesCursor {
+		c.Abort()
+		apiErrors.ErrInvalidPageSettings.Emit(c)
+		return
+	}
+
+	var search structs.ConsumerSearch
+	_ = c.ShouldBindQuery(&search)
+	search.Search = strings.TrimSpace(search.Search)
+
+	q, err := db.Queries.Raw("count-consumers")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var total int64
+	err = pgxscan.Get(c, db.Pool, &total, q, search.Search)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	q, err = db.Queries.Raw("search-consumers")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var consumers []structs.Consumer
+	err = pgxscan.Select(c, db.Pool, &consumers, q, search.Search, c.GetInt(KeyPageSize), c.GetInt(KeyPageOffset))
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header(HeaderTotalCount, strconv.FormatInt(total, 10))
+	links := pageLinks(c, "", &total)
+	c.Header("Link", routeUtils.FormatLinks(links))
+	sendJSON(c, consumers, pageMeta(c, &total), links)
+}
+
+func ConsumerSummary(c *gin.Context) {
+	consumerID := strings.TrimSpace(c.Param("consumerID"))
+
+	if serviceErr := internal.ValidateConsumerID(consumerID); serviceErr != nil {
+		c.Abort()
+		serviceErr.Emit(c)
+		return
+	}
+
+	var exists bool
+	q, err := db.Queries.Raw("consumer-exists")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+	err = pgxscan.Get(c, db.Pool, &exists, q, consumerID)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	if !exists {
+		c.Abort()
+		apiErrors.ErrUnknownConsumer.Emit(c)
+		return
+	}
+
+	q, err = db.Queries.Raw("consumer-summary")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var summary structs.ConsumerSummary
+	err = pgxscan.Get(c, db.Pool, &summary, q, consumerID)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+	summary.ConsumerID = consumerID
+
+	c.JSON(200, summary)
+}
@@ -0,0 +1,147 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	apiErrors "microservice/internal/errors"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func _consumers(t *testing.T) {
+	t.Run("List", _c_list)
+	t.Run("Search", _c_search)
+	t.Run("Cursor", _c_cursor)
+	t.Run("Summary", _c_summary)
+	t.Run("Unknown_Consumer_Summary", _c_unknown_consumer_summary)
+}
+
+func _c_list(t *testing.T) {
+	apiPath := "consumers"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?pageSize=5", routePrefix, apiPath), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	assert.NotEmpty(t, res.Header().Get(HeaderTotalCount))
+	assert.Contains(t, res.Header().Get("Link"), `rel="first"`)
+
+	var consumers []structs.Consumer
+	err = json.NewDecoder(res.Body).Decode(&consumers)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(consumers), 5)
+}
+
+func _c_search(t *testing.T) {
+	apiPath := "consumers"
+	search := "a"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?search=%s", routePrefix, apiPath, search), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var consumers []structs.Consumer
+	err := json.NewDecoder(res.Body).Decode(&consumers)
+	assert.NoError(t, err)
+	for _, consumer := range consumers {
+		assert.Contains(t, strings.ToLower(consumer.Name), search)
+	}
+}
+
+func _c_cursor(t *testing.T) {
+	apiPath := "consumers"
+	expectedError := apiErrors.ErrInvalidPageSettings
+
+	cursor, err := routeUtils.EncodeCursor(structs.UsageCursor{Time: time.Now()})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?cursor=%s", routePrefix, apiPath, cursor), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _c_summary(t *testing.T) {
+	apiPath := "consumers"
+	pathParameter := `390dc645-c0a4-4cdf-8fbd-ab151f8c9687`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s/summary", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var summary structs.ConsumerSummary
+	err = json.NewDecoder(res.Body).Decode(&summary)
+	assert.NoError(t, err)
+
+	assert.Equal(t, pathParameter, summary.ConsumerID)
+	if summary.Readings > 0 {
+		assert.True(t, summary.FirstReading.Valid)
+		assert.False(t, summary.LastReading.Time.Before(summary.FirstReading.Time))
+	}
+}
+
+func _c_unknown_consumer_summary(t *testing.T) {
+	apiPath := "consumers"
+	pathParameter := uuid.NewString()
+	expectedError := apiErrors.ErrUnknownConsumer
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s/summary", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
@@ -0,0 +1,79 @@
+package routes
+
+import (
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// filterParameters contains the query parameters which restrict the data of a
+// response and are therefore listed as filters in the envelope
+var filterParameters = []string{"from", "until", "consumer", "usageType", "ars", "search"}
+
+// sendJSON sends the data as JSON response. If requested, the data is wrapped
+// into an envelope containing the metadata and links of the response. The
+// filters of the request and the generation time are added to the metadata
+func sendJSON(c *gin.Context, data any, meta structs.EnvelopeMeta, links []routeUtils.Link) {
+	if !c.GetBool(KeyEnvelope) {
+		c.JSON(200, data)
+		return
+	}
+
+	meta.Filters = requestFilters(c)
+	meta.GeneratedAt = time.Now().UTC()
+
+	envelope := structs.Envelope{
+		Data:  data,
+		Meta:  meta,
+		Links: make(map[string]string, len(links)),
+	}
+	for _, link := range links {
+		envelope.Links[link.Rel] = link.Target
+	}
+	c.JSON(200, envelope)
+}
+
+// pageMeta returns the metadata of a paginated response containing the
+// page settings read by routeUtils.ReadPageSettings and the total count. Pages
+// requested using a cursor have no page number
+func pageMeta(c *gin.Context, total *int64) structs.EnvelopeMeta {
+	pageSize := c.GetInt(KeyPageSize)
+	meta := structs.EnvelopeMeta{
+		PageSize: &pageSize,
+		Total:    total,
+	}
+	if _, usesCursor := c.Get(KeyPageCursor); !usesCursor {
+		page := c.GetInt(KeyPageOffset)/pageSize + 1
+		meta.Page = &page
+	}
+	return meta
+}
+
+// usageMeta adds the unit of the usage amounts to the metadata
+func usageMeta(meta structs.EnvelopeMeta) structs.EnvelopeMeta {
+	unit := UsageUnit
+	meta.Unit = &unit
+	return meta
+}
+
+// requestFilters collects the path parameters and filter query parameters of
+// the request
+func requestFilters(c *gin.Context) map[string][]string {
+	filters := make(map[string][]string)
+	for _, parameter := range c.Params {
+		if value := strings.ReplaceAll(strings.TrimSpace(parameter.Value), "/", ""); value != "" {
+			filters[parameter.Key] = []string{value}
+		}
+	}
+
+	query := c.Request.URL.Query()
+	for _, parameter := range filterParameters {
+		if values, isSet := query[parameter]; isSet {
+			filters[parameter] = append(filters[parameter], values...)
+		}
+	}
+	return filters
+}
@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"errors"
+	apiErrors "microservice/internal/errors"
+	"microservice/internal/ingest"
+	"microservice/structs"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ImportUsages(c *gin.Context) {
+	var settings structs.ImportSettings
+	if err := c.ShouldBindQuery(&settings); err != nil {
+		c.Abort()
+		apiErrors.ErrInvalidImportSettings.Emit(c)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.Abort()
+		apiErrors.ErrMissingImportFile.Emit(c)
+		return
+	}
+
+	format, err := ingest.FileFormat(fileHeader.Filename)
+	if err != nil {
+		c.Abort()
+		emitInvalidImportFile(c, err)
+		return
+	}
+
+	options := ingest.Options{
+		Format:           format,
+		Delimiter:        c.MustGet(KeyCSVDelimiter).(rune),
+		DecimalSeparator: c.GetString(KeyCSVDecimalSeparator),
+		Sheet:            settings.Sheet,
+		TimeFormat:       settings.TimeFormat,
+		Columns:          settings.ColumnMapping,
+		DryRun:           settings.DryRun,
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+	defer file.Close()
+
+	table, err := ingest.ReadTable(file, options)
+	if err != nil {
+		c.Abort()
+		emitInvalidImportFile(c, err)
+		return
+	}
+
+	report, err := ingest.Import(c, table, options)
+	if errors.Is(err, ingest.ErrMissingColumn) {
+		c.Abort()
+		emitInvalidImportFile(c, err)
+		return
+	}
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+// emitInvalidImportFile emits the ErrInvalidImportFile error containing the
+// reason why the file could not be imported
+func emitInvalidImportFile(c *gin.Context, reason error) {
+	serviceErr := apiErrors.ErrInvalidImportFile
+	serviceErr.Errors = []error{reason}
+	serviceErr.Emit(c)
+}
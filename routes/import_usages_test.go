@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	apiErrors "microservice/internal/errors"
+	"microservice/structs"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func _import_usages(t *testing.T) {
+	t.Run("Missing_File", _im_missing_file)
+	t.Run("Missing_Column", _im_missing_column)
+	t.Run("Dry_Run", _im_dry_run)
+}
+
+// importRequest creates a multipart request uploading the content as file
+func importRequest(t *testing.T, query string, filename string, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("%s/import?%s", routePrefix, query), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func _im_missing_file(t *testing.T) {
+	expectedError := apiErrors.ErrMissingImportFile
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("%s/import", routePrefix), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _im_missing_column(t *testing.T) {
+	expectedError := apiErrors.ErrInvalidImportFile
+	content := "Datum;Menge;ARS\n2024-01-01T00:00:00Z;12,5;031515401020\n"
+
+	req := importRequest(t, "dryRun=true&delimiter=;&decimalSeparator=,", "readings.csv", content)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _im_dry_run(t *testing.T) {
+	content := "Datum;Menge;ARS\n" +
+		"2024-01-01T00:00:00Z;12,5;031515401020\n" +
+		"2024-01-02T00:00:00Z;viel;031515401020\n" +
+		"2024-01-03T00:00:00Z;3,5;0315\n" +
+		"2024-01-01T00:00:00Z;12,5;031515401020\n"
+	query := "dryRun=true&delimiter=;&decimalSeparator=,&timeColumn=Datum&amountColumn=Menge&arsColumn=ARS"
+
+	req := importRequest(t, query, "readings.csv", content)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var report structs.ImportReport
+	err = json.NewDecoder(res.Body).Decode(&report)
+	assert.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 4, report.Rows)
+	assert.Equal(t, 1, report.Valid)
+	assert.Zero(t, report.Inserted)
+	if assert.Len(t, report.Issues, 3) {
+		assert.Equal(t, 3, report.Issues[0].Row)
+		assert.Equal(t, 4, report.Issues[1].Row)
+		assert.Equal(t, 5, report.Issues[2].Row)
+	}
+}
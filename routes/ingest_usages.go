@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"microservice/internal/config"
+	apiErrors "microservice/internal/errors"
+	"microservice/internal/ingest"
+	"microservice/structs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func IngestUsage(c *gin.Context) {
+	var record structs.UsageRecord
+	if !bindUsageRecords(c, &record) {
+		return
+	}
+
+	if !validateUsageRecords(c, []structs.UsageRecord{record}) {
+		return
+	}
+
+	err := ingest.Insert(c, record)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, record)
+}
+
+func IngestUsageBatch(c *gin.Context) {
+	var records []structs.UsageRecord
+	if !bindUsageRecords(c, &records) {
+		return
+	}
+
+	if len(records) == 0 {
+		c.Abort()
+		apiErrors.ErrEmptyBatch.Emit(c)
+		return
+	}
+
+	if maxRecords := config.Current.Ingest.MaxBatchRecords; len(records) > maxRecords {
+		c.Abort()
+		serviceErr := apiErrors.ErrBatchTooLarge
+		serviceErr.Errors = []error{fmt.Errorf("the batch contains %d usage records, at most %d are accepted", len(records), maxRecords)}
+		serviceErr.Emit(c)
+		return
+	}
+
+	if !validateUsageRecords(c, records) {
+		return
+	}
+
+	inserted, err := ingest.Store(c, records)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.IngestionResult{Inserted: inserted})
+}
+
+// bindUsageRecords parses the request body into the destination. The body is
+// limited to the configured size. If the body is too large or malformed, the
+// matching error is emitted, the request is aborted and false is returned
+func bindUsageRecords(c *gin.Context, destination any) bool {
+	maxBodySize := config.Current.Ingest.MaxBodySize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize)
+
+	err := c.ShouldBindJSON(destination)
+	if err == nil {
+		return true
+	}
+
+	c.Abort()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		serviceErr := apiErrors.ErrBatchTooLarge
+		serviceErr.Errors = []error{fmt.Errorf("the request body exceeds %d bytes", maxBodySize)}
+		serviceErr.Emit(c)
+		return false
+	}
+	apiErrors.ErrMalformedUsageRecords.Emit(c)
+	return false
+}
+
+// validateUsageRecords validates the usage records and emits the issues found
+// in the records. If the usage records are invalid or the validation failed,
+// the request is aborted and false is returned
+func validateUsageRecords(c *gin.Context, records []structs.UsageRecord) bool {
+	issues, err := ingest.NewValidator().Validate(c, records)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return false
+	}
+
+	if len(issues) > 0 {
+		c.Abort()
+		serviceErr := apiErrors.ErrInvalidUsageRecords
+		serviceErr.Errors = issues
+		serviceErr.Emit(c)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,184 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservice/internal/config"
+	apiErrors "microservice/internal/errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func _ingest_usages(t *testing.T) {
+	t.Run("Malformed_Record", _iu_malformed_record)
+	t.Run("Invalid_Record", _iu_invalid_record)
+	t.Run("Empty_Batch", _iu_empty_batch)
+	t.Run("Invalid_Batch", _iu_invalid_batch)
+	t.Run("Oversized_Batch", _iu_oversized_batch)
+}
+
+func _iu_malformed_record(t *testing.T) {
+	body := `{"time": "yesterday", "amount": "a lot"}`
+	expectedError := apiErrors.ErrMalformedUsageRecords
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("%s/", routePrefix), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _iu_invalid_record(t *testing.T) {
+	body := `{"time": "2024-01-01T00:00:00Z", "amount": 12.5, "ars": "0315"}`
+	expectedError := apiErrors.ErrInvalidUsageRecords
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("%s/", routePrefix), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+	assert.Len(t, receivedError.Errors, 1)
+}
+
+func _iu_empty_batch(t *testing.T) {
+	body := `[]`
+	expectedError := apiErrors.ErrEmptyBatch
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("%s/batch", routePrefix), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _iu_invalid_batch(t *testing.T) {
+	body := `[
+		{"time": "2024-01-01T00:00:00Z", "amount": 12.5, "ars": "031515401020"},
+		{"amount": 12.5, "ars": "031515401020", "consumerID": "00000000-0000-0000-0000-000000000000"},
+		{"time": "2024-01-01T00:00:00Z", "amount": 12.5, "ars": "031515401020", "usageType": "industrial"}
+	]`
+	expectedError := apiErrors.ErrInvalidUsageRecords
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("%s/batch", routePrefix), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+	// the first record is valid, the second record is missing the time and
+	// references an unknown consumer and the third record has an invalid
+	// usage type
+	assert.Len(t, receivedError.Errors, 3)
+}
+
+func _iu_oversized_batch(t *testing.T) {
+	limits := config.Current.Ingest
+	t.Cleanup(func() { config.Current.Ingest = limits })
+
+	record := `{"time": "2024-01-01T00:00:00Z", "amount": 12.5, "ars": "031515401020"}`
+	tests := []struct {
+		name   string
+		limits config.IngestConfiguration
+	}{
+		{"Too_Many_Records", config.IngestConfiguration{MaxBodySize: limits.MaxBodySize, MaxBatchRecords: 2}},
+		{"Too_Large_Body", config.IngestConfiguration{MaxBodySize: int64(len(record)), MaxBatchRecords: limits.MaxBatchRecords}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config.Current.Ingest = test.limits
+			body := "[" + strings.Join([]string{record, record, record}, ",") + "]"
+			expectedError := apiErrors.ErrBatchTooLarge
+
+			req := httptest.NewRequest("POST", fmt.Sprintf("%s/batch", routePrefix), strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			res := httptest.NewRecorder()
+
+			r.Handler().ServeHTTP(res, req)
+			assert.Equal(t, int(expectedError.Status), res.Code)
+
+			err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+			if err != nil {
+				t.Fail()
+				t.Log(err)
+			}
+
+			var receivedError types.ServiceError
+			err = json.NewDecoder(res.Body).Decode(&receivedError)
+			assert.NoError(t, err)
+			if t.Failed() {
+				t.FailNow()
+			}
+
+			assert.True(t, receivedError.Equals(expectedError))
+		})
+	}
+}
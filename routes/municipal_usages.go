@@ -1,45 +1,67 @@
 package routes
 
 import (
+	"microservice/internal"
 	"microservice/internal/db"
 	apiErrors "microservice/internal/errors"
 	"microservice/structs"
 	"strings"
 
-	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
 )
 
 func MunicipalUsages(c *gin.Context) {
 	ars := strings.ReplaceAll(strings.TrimSpace(c.Param("ars")), "/", "")
 
-	if ars == "" {
+	if serviceErr := internal.ValidateARSPrefix(ars); serviceErr != nil {
 		c.Abort()
-		apiErrors.ErrEmptyARS.Emit(c)
+		serviceErr.Emit(c)
 		return
 	}
 
-	if len(ars) != 12 {
+	var settings structs.RollupSettings
+	if err := c.ShouldBindQuery(&settings); err != nil {
 		c.Abort()
-		apiErrors.ErrInvalidARS.Emit(c)
+		apiErrors.ErrInvalidRollupSettings.Emit(c)
 		return
 	}
 
-	q, err := db.Queries.Raw("municipal-usages")
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
-		return
+	region := structs.MustParseARS(ars)
+
+	// a municipal ARS identifies a single municipality, every shorter ARS
+	// identifies a region containing all municipalities below it
+	var query db.UsageQuery
+	if region.Municipal() {
+		query.Where(db.ColumnMunicipality, ars)
+	} else {
+		query.WherePrefix(db.ColumnMunicipality, ars)
 	}
+	applyTimeRange(c, &query)
 
-	var records []structs.UsageRecord
-	err = pgxscan.Select(c, db.Pool, &records, q, ars, c.GetInt(KeyPageSize), c.GetInt(KeyPageOffset))
-	if err != nil {
-		c.Abort()
-		_ = c.Error(err)
+	if settings.Rollup {
+		if answerConditional(c) {
+			return
+		}
+
+		q, args := query.BuildRollup(region.ChildLength())
+
+		var regions []structs.RegionUsage
+		err := db.CachedSelect(c, &regions, q, args...)
+		if err != nil {
+			c.Abort()
+			_ = c.Error(err)
+			return
+		}
+
+		sendJSON(c, regions, usageMeta(structs.EnvelopeMeta{}), nil)
 		return
 	}
 
-	c.JSON(200, records)
-
+	if !applySort(c, &query) {
+		return
+	}
+	if !applyPagination(c, &query) {
+		return
+	}
+	sendUsages(c, query, "usages-municipality-"+ars)
 }
@@ -1,15 +1,20 @@
 package routes
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	apiErrors "microservice/internal/errors"
+	"microservice/structs"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/parquet-go/parquet-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/wisdom-oss/common-go/v3/types"
 )
@@ -18,7 +23,16 @@ func _municipal_usages(t *testing.T) {
 
 	t.Run("Empty_ARS", _mu_empty_ars)
 	t.Run("Invalid_ARS", _mu_invalid_ars)
+	t.Run("Invalid_Land", _mu_invalid_land)
+	t.Run("Non_Numeric_ARS", _mu_non_numeric_ars)
 	t.Run("Valid_Request", _mu_valid_request)
+	t.Run("Region_Prefix", _mu_region_prefix)
+	t.Run("Rollup", _mu_rollup)
+	t.Run("Expand_Municipality", _mu_expand_municipality)
+	t.Run("Conditional_Request", _mu_conditional_request)
+	t.Run("CSV_Export", _mu_csv_export)
+	t.Run("NDJSON_Export", _mu_ndjson_export)
+	t.Run("Parquet_Export", _mu_parquet_export)
 }
 
 func _mu_empty_ars(t *testing.T) {
@@ -75,6 +89,66 @@ func _mu_invalid_ars(t *testing.T) {
 	assert.True(t, receivedError.Equals(expectedError))
 }
 
+func _mu_invalid_land(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `171515401020`
+	expectedError := apiErrors.ErrInvalidARS
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+	if assert.Len(t, receivedError.Errors, 1) {
+		assert.ErrorContains(t, receivedError.Errors[0], "Land")
+	}
+}
+
+func _mu_non_numeric_ars(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `03151540a020`
+	expectedError := apiErrors.ErrInvalidARS
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+	if assert.Len(t, receivedError.Errors, 1) {
+		assert.ErrorContains(t, receivedError.Errors[0], "Gemeindeverband")
+	}
+}
+
 func _mu_valid_request(t *testing.T) {
 	apiPath := "municipal"
 	pathParameter := `031515401020`
@@ -91,3 +165,189 @@ func _mu_valid_request(t *testing.T) {
 		t.Log(err)
 	}
 }
+
+func _mu_region_prefix(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `03151`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var records []structs.UsageRecord
+	err = json.NewDecoder(res.Body).Decode(&records)
+	assert.NoError(t, err)
+	for _, record := range records {
+		assert.True(t, strings.HasPrefix(*record.ARS, pathParameter))
+	}
+}
+
+func _mu_rollup(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `03151`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s?rollup=true", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var regions []structs.RegionUsage
+	err = json.NewDecoder(res.Body).Decode(&regions)
+	assert.NoError(t, err)
+	for _, region := range regions {
+		assert.Len(t, region.ARS, 9)
+		assert.True(t, strings.HasPrefix(region.ARS, pathParameter))
+	}
+}
+
+func _mu_expand_municipality(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `034030000000`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s?expand=municipality", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var records []structs.UsageRecord
+	err = json.NewDecoder(res.Body).Decode(&records)
+	assert.NoError(t, err)
+	for _, record := range records {
+		if assert.NotNil(t, record.MunicipalityName) {
+			assert.Equal(t, "Oldenburg (Oldb)", *record.MunicipalityName)
+		}
+	}
+}
+
+func _mu_conditional_request(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `031515401020`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, CacheControl, res.Header().Get("Cache-Control"))
+
+	etag := res.Header().Get("ETag")
+	if !assert.NotEmpty(t, etag) {
+		t.FailNow()
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	req.Header.Set("If-None-Match", etag)
+	res = httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusNotModified, res.Code)
+	assert.Equal(t, etag, res.Header().Get("ETag"))
+	assert.Empty(t, res.Body.Bytes())
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+}
+
+func _mu_csv_export(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `031515401020`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s?delimiter=;&decimalSeparator=,", routePrefix, apiPath, pathParameter), nil)
+	req.Header.Set("Accept", "text/csv")
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	assert.True(t, strings.HasPrefix(res.Header().Get("Content-Type"), "text/csv"))
+	assert.Contains(t, res.Header().Get("Content-Disposition"), pathParameter)
+
+	header, _, _ := strings.Cut(res.Body.String(), "\n")
+	assert.Equal(t, "time;amount;usageType;consumerID;ars", header)
+}
+
+func _mu_ndjson_export(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `031515401020`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	assert.Empty(t, res.Header().Get("Link"))
+
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		var record structs.UsageRecord
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		assert.NoError(t, err)
+		assert.Equal(t, pathParameter, *record.ARS)
+	}
+	assert.NoError(t, scanner.Err())
+}
+
+func _mu_parquet_export(t *testing.T) {
+	apiPath := "municipal"
+	pathParameter := `031515401020`
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s?format=parquet", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	assert.Contains(t, res.Header().Get("Content-Disposition"), ".parquet")
+
+	body := res.Body.Bytes()
+	rows, err := parquet.Read[usageParquetRow](bytes.NewReader(body), int64(len(body)))
+	assert.NoError(t, err)
+	for _, row := range rows {
+		assert.Equal(t, pathParameter, *row.ARS)
+	}
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"fmt"
+	"microservice/internal/db"
+	"microservice/structs"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/gin-gonic/gin"
+)
+
+func Municipalities(c *gin.Context) {
+	q, err := db.Queries.Raw("get-municipalities")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var municipalities []structs.Municipality
+	err = pgxscan.Select(c, db.Pool, &municipalities, q)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	// the regions are not validated by the database, therefore a malformed
+	// region fails the request instead of the service
+	for _, municipality := range municipalities {
+		for i, parent := range municipality.Parents {
+			ars, err := structs.ParseARS(parent.ARS)
+			if err != nil {
+				c.Abort()
+				_ = c.Error(fmt.Errorf("invalid region in the registry: %w", err))
+				return
+			}
+			municipality.Parents[i].Level = ars.Level()
+		}
+	}
+
+	sendJSON(c, municipalities, structs.EnvelopeMeta{}, nil)
+}
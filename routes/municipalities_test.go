@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservice/structs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func _municipalities(t *testing.T) {
+	t.Run("Valid_Request", _m_valid_request)
+}
+
+func _m_valid_request(t *testing.T) {
+	apiPath := "municipalities"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s", routePrefix, apiPath), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var municipalities []structs.Municipality
+	err = json.NewDecoder(res.Body).Decode(&municipalities)
+	assert.NoError(t, err)
+
+	var oldenburg *structs.Municipality
+	for _, municipality := range municipalities {
+		if municipality.ARS == "034030000000" {
+			oldenburg = &municipality
+		}
+	}
+	if assert.NotNil(t, oldenburg) {
+		assert.Equal(t, "Oldenburg (Oldb)", oldenburg.Name)
+		assert.Equal(t, []structs.Region{
+			{ARS: "03", Name: "Niedersachsen", Level: "Land"},
+			{ARS: "034", Name: "Weser-Ems", Level: "Regierungsbezirk"},
+			{ARS: "03403", Name: "Oldenburg (Oldb)", Level: "Kreis"},
+		}, oldenburg.Parents)
+	}
+}
@@ -4,19 +4,38 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	apiErrors "microservice/internal/errors"
+	routeUtils "microservice/routes/utils"
 	"microservice/structs"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
 )
 
 func _paged_usages(t *testing.T) {
 	t.Run("Defaults", _pu_defaults)
 	t.Run("Pagination", _pu_pages)
 	t.Run("Sizing", _pu_page_size)
+	t.Run("Cursor", _pu_cursor)
+	t.Run("Total_Count", _pu_total_count)
+	t.Run("Sort", _pu_sort)
+	t.Run("Sorted_Cursor", _pu_sorted_cursor)
+	t.Run("Resorted_Cursor", _pu_resorted_cursor)
+	t.Run("Invalid_Sort", _pu_invalid_sort)
+	t.Run("Fields", _pu_fields)
+	t.Run("CSV_Fields", _pu_csv_fields)
+	t.Run("Invalid_Fields", _pu_invalid_fields)
+	t.Run("Parquet_Fields", _pu_parquet_fields)
+	t.Run("Parquet_Expansion", _pu_parquet_expansion)
+	t.Run("Envelope", _pu_envelope)
+	t.Run("Cursor_Envelope", _pu_cursor_envelope)
+	t.Run("Envelope_Profile", _pu_envelope_profile)
 }
 
 func _pu_defaults(t *testing.T) {
@@ -95,3 +114,374 @@ func _pu_page_size(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, entries, expectedEntries)
 }
+
+func _pu_cursor(t *testing.T) {
+	pageSize := 1000
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d", routePrefix, pageSize), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	cursor := res.Header().Get(HeaderNextCursor)
+	assert.NotEmpty(t, cursor)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&cursor=%s", routePrefix, pageSize, cursor), nil)
+	res = httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err = openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var cursorPage []structs.UsageRecord
+	err = json.NewDecoder(res.Result().Body).Decode(&cursorPage)
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&page=2", routePrefix, pageSize), nil)
+	res = httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var offsetPage []structs.UsageRecord
+	err = json.NewDecoder(res.Result().Body).Decode(&offsetPage)
+	assert.NoError(t, err)
+
+	assert.Equal(t, offsetPage, cursorPage)
+}
+
+func _pu_total_count(t *testing.T) {
+	pageSize := 1000
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&page=2&count=true", routePrefix, pageSize), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	total, err := strconv.Atoi(res.Header().Get(HeaderTotalCount))
+	assert.NoError(t, err)
+	assert.Greater(t, total, pageSize)
+
+	links := res.Header().Get("Link")
+	assert.Contains(t, links, `rel="first"`)
+	assert.Contains(t, links, `rel="prev"`)
+	assert.Contains(t, links, `rel="last"`)
+}
+
+func _pu_sort(t *testing.T) {
+	req := httptest.NewRequest("GET", routePrefix+"/?pageSize=1000&sort=-amount,time", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var entries []structs.UsageRecord
+	err = json.NewDecoder(res.Result().Body).Decode(&entries)
+	assert.NoError(t, err)
+
+	for i := 1; i < len(entries); i++ {
+		previous, current := entries[i-1], entries[i]
+		assert.GreaterOrEqual(t, previous.Amount, current.Amount)
+		if previous.Amount == current.Amount {
+			assert.False(t, current.Time.Time.Before(previous.Time.Time))
+		}
+	}
+}
+
+func _pu_sorted_cursor(t *testing.T) {
+	pageSize := 1000
+	sort := "-amount,ars"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&sort=%s", routePrefix, pageSize, sort), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	cursor := res.Header().Get(HeaderNextCursor)
+	assert.NotEmpty(t, cursor)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&sort=%s&cursor=%s", routePrefix, pageSize, sort, cursor), nil)
+	res = httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var cursorPage []structs.UsageRecord
+	err := json.NewDecoder(res.Result().Body).Decode(&cursorPage)
+	assert.NoError(t, err)
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&sort=%s&page=2", routePrefix, pageSize, sort), nil)
+	res = httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var offsetPage []structs.UsageRecord
+	err = json.NewDecoder(res.Result().Body).Decode(&offsetPage)
+	assert.NoError(t, err)
+
+	assert.Equal(t, offsetPage, cursorPage)
+}
+
+func _pu_resorted_cursor(t *testing.T) {
+	expectedError := apiErrors.ErrInvalidCursor
+	pageSize := 1000
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&sort=-amount", routePrefix, pageSize), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	cursor := res.Header().Get(HeaderNextCursor)
+	assert.NotEmpty(t, cursor)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&sort=amount&cursor=%s", routePrefix, pageSize, cursor), nil)
+	res = httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _pu_invalid_sort(t *testing.T) {
+	expectedError := apiErrors.ErrInvalidSortField
+
+	req := httptest.NewRequest("GET", routePrefix+"/?sort=-name", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+	if assert.Len(t, receivedError.Errors, 1) {
+		assert.ErrorContains(t, receivedError.Errors[0], "name")
+	}
+}
+
+func _pu_fields(t *testing.T) {
+	req := httptest.NewRequest("GET", routePrefix+"/?pageSize=100&fields=ars,amount", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	assert.NotEmpty(t, res.Header().Get(HeaderNextCursor))
+
+	var entries []map[string]any
+	err = json.NewDecoder(res.Result().Body).Decode(&entries)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 100)
+	for _, entry := range entries {
+		assert.Len(t, entry, 2)
+		assert.Contains(t, entry, "ars")
+		assert.Contains(t, entry, "amount")
+	}
+}
+
+func _pu_csv_fields(t *testing.T) {
+	req := httptest.NewRequest("GET", routePrefix+"/?pageSize=100&format=csv&fields=ars,amount", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	header, _, _ := strings.Cut(res.Body.String(), "\n")
+	assert.Equal(t, "amount,ars", header)
+}
+
+func _pu_invalid_fields(t *testing.T) {
+	expectedError := apiErrors.ErrInvalidFields
+
+	req := httptest.NewRequest("GET", routePrefix+"/?fields=ars,municipality", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _pu_parquet_fields(t *testing.T) {
+	expectedError := apiErrors.ErrInvalidFields
+
+	req := httptest.NewRequest("GET", routePrefix+"/?format=parquet&fields=ars,amount", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _pu_parquet_expansion(t *testing.T) {
+	expectedError := apiErrors.ErrInvalidExpansion
+
+	req := httptest.NewRequest("GET", routePrefix+"/?format=parquet&expand=municipality", nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _pu_envelope(t *testing.T) {
+	pageSize := 100
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&page=2&count=true&envelope=true", routePrefix, pageSize), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var envelope struct {
+		Data  []structs.UsageRecord `json:"data"`
+		Meta  structs.EnvelopeMeta  `json:"meta"`
+		Links map[string]string     `json:"links"`
+	}
+	err = json.NewDecoder(res.Result().Body).Decode(&envelope)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.Len(t, envelope.Data, pageSize)
+	assert.Equal(t, 2, *envelope.Meta.Page)
+	assert.Equal(t, pageSize, *envelope.Meta.PageSize)
+	assert.Equal(t, res.Header().Get(HeaderTotalCount), strconv.FormatInt(*envelope.Meta.Total, 10))
+	assert.Equal(t, UsageUnit, *envelope.Meta.Unit)
+	assert.Contains(t, envelope.Links, "prev")
+	assert.Contains(t, envelope.Links, "next")
+}
+
+func _pu_cursor_envelope(t *testing.T) {
+	pageSize := 100
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d", routePrefix, pageSize), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	cursor := res.Header().Get(HeaderNextCursor)
+	assert.NotEmpty(t, cursor)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	req = httptest.NewRequest("GET", fmt.Sprintf("%s/?pageSize=%d&cursor=%s&envelope=true", routePrefix, pageSize, cursor), nil)
+	res = httptest.NewRecorder()
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var envelope struct {
+		Meta structs.EnvelopeMeta `json:"meta"`
+	}
+	err = json.NewDecoder(res.Result().Body).Decode(&envelope)
+	assert.NoError(t, err)
+
+	assert.Nil(t, envelope.Meta.Page)
+	assert.Equal(t, pageSize, *envelope.Meta.PageSize)
+}
+
+func _pu_envelope_profile(t *testing.T) {
+	req := httptest.NewRequest("GET", routePrefix+"/?pageSize=10&from=2021-01-01T00:00:00Z", nil)
+	req.Header.Set("Accept", `application/json; profile="`+routeUtils.EnvelopeProfile+`"`)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	var envelope structs.Envelope
+	err := json.NewDecoder(res.Result().Body).Decode(&envelope)
+	assert.NoError(t, err)
+
+	assert.NotNil(t, envelope.Data)
+	assert.Equal(t, []string{"2021-01-01T00:00:00Z"}, envelope.Meta.Filters["from"])
+	assert.False(t, envelope.Meta.GeneratedAt.IsZero())
+}
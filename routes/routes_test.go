@@ -3,11 +3,15 @@ package routes
 import (
 	"context"
 	"encoding/json"
+	"microservice/internal/config"
+	"microservice/internal/db"
 	apiErrors "microservice/internal/errors"
 	routeUtils "microservice/routes/utils"
+	"microservice/structs"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -41,18 +45,47 @@ func TestRoutes(t *testing.T) {
 
 	openapi = doc
 
+	// the streamed responses are validated as plain strings as the
+	// validation library does not provide a decoder for newline delimited
+	// json documents and parquet files
+	openapi3filter.RegisterBodyDecoder(routeUtils.MIMENDJSON, openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(routeUtils.MIMEParquet, openapi3filter.FileBodyDecoder)
+
+	db.Connect()
+
 	r = gin.New()
 	r.Use(routeUtils.ReadPageSettings)
+	r.Use(routeUtils.ReadTimeRange)
+	r.Use(routeUtils.ReadOutputSettings)
 	r.GET("/", PagedUsages)
 	r.GET("/consumer/*consumerID", ConsumerUsages)
 	r.GET("/municipal/*ars", MunicipalUsages)
 	r.GET("/type/*usageTypeID", TypedUsages)
+	r.GET("/search", SearchUsages)
+	r.GET("/aggregate", AggregateUsages)
+	r.GET("/municipalities", Municipalities)
+	r.GET("/types", UsageTypes)
+	r.GET("/types/:usageTypeID", UsageType)
+	r.GET("/consumers", Consumers)
+	r.GET("/consumers/:consumerID/summary", ConsumerSummary)
+	r.POST("/", IngestUsage)
+	r.POST("/batch", IngestUsageBatch)
+	r.POST("/import", ImportUsages)
 
 	t.Run("Paged_Usages", _paged_usages)
 	t.Run("Consumer_Usages", _consumer_usages)
 	t.Run("Municipal_Usages", _municipal_usages)
 	t.Run("Typed_Usages", _typed_usages)
+	t.Run("Search_Usages", _search_usages)
+	t.Run("Aggregate_Usages", _aggregate_usages)
+	t.Run("Municipalities", _municipalities)
+	t.Run("Usage_Types", _usage_types)
+	t.Run("Consumers", _consumers)
+	t.Run("Ingest_Usages", _ingest_usages)
+	t.Run("Import_Usages", _import_usages)
 	t.Run("Page_Settings", _page_settings)
+	t.Run("Time_Range", _time_range)
+	t.Run("Output_Settings", _output_settings)
 }
 
 func generateValidationData(t *testing.T, req *http.Request, res *httptest.ResponseRecorder) *openapi3filter.ResponseValidationInput {
@@ -143,4 +176,226 @@ func _page_settings(t *testing.T) {
 		assert.True(t, receivedError.Equals(expectedError))
 
 	})
+
+	t.Run("Invalid_Cursor", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidCursor
+
+		req := httptest.NewRequest("GET", routePrefix+"/?cursor=not-a-cursor", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Cursor_With_Page", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidPageSettings
+
+		req := httptest.NewRequest("GET", routePrefix+"/?page=2&cursor=e30", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Conflicting_Page_Sizes", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidPageSettings
+
+		req := httptest.NewRequest("GET", routePrefix+"/?size=5&pageSize=6", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Route_Page_Limits", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidPageSettings
+
+		defaultRoutes := config.Current.Pagination.Routes
+		config.Current.Pagination.Routes = map[string]config.PageLimits{
+			"/consumers": {DefaultPageSize: 5, MaxPageSize: 10},
+		}
+		defer func() { config.Current.Pagination.Routes = defaultRoutes }()
+
+		req := httptest.NewRequest("GET", routePrefix+"/consumers?size=11", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Documented_Page_Limits", func(t *testing.T) {
+		limits := config.Defaults().Pagination.PageLimits
+
+		for _, name := range []string{"Size", "PageSize"} {
+			parameter := openapi.Components.Parameters[name]
+			if !assert.NotNil(t, parameter, name) {
+				continue
+			}
+			schema := parameter.Value.Schema.Value
+			assert.EqualValues(t, limits.DefaultPageSize, schema.Default, name)
+			if assert.NotNil(t, schema.Max, name) {
+				assert.EqualValues(t, limits.MaxPageSize, *schema.Max, name)
+			}
+		}
+	})
+}
+
+func _time_range(t *testing.T) {
+	t.Run("Invalid_Timestamp", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidTimestamp
+
+		req := httptest.NewRequest("GET", routePrefix+"/?from=yesterday", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Inverted_Range", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvertedTimeRange
+
+		req := httptest.NewRequest("GET", routePrefix+"/?from=2024-01-01T00:00:00Z&until=2023-01-01T00:00:00Z", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Valid_Range", func(t *testing.T) {
+		from, _ := time.Parse(time.RFC3339, "2023-01-01T00:00:00Z")
+		until, _ := time.Parse(time.RFC3339, "2024-01-01T00:00:00Z")
+
+		req := httptest.NewRequest("GET", routePrefix+"/?from=2023-01-01T00:00:00Z&until=2024-01-01T00:00:00Z", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+		if err != nil {
+			t.Fail()
+			t.Log(err)
+		}
+
+		var entries []structs.UsageRecord
+		err = json.NewDecoder(res.Body).Decode(&entries)
+		assert.NoError(t, err)
+		for _, entry := range entries {
+			assert.False(t, entry.Time.Time.Before(from))
+			assert.True(t, entry.Time.Time.Before(until))
+		}
+	})
+}
+
+func _output_settings(t *testing.T) {
+	t.Run("Unsupported_Format", func(t *testing.T) {
+		expectedError := apiErrors.ErrUnsupportedFormat
+
+		req := httptest.NewRequest("GET", routePrefix+"/?format=xml", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Ambiguous_CSV_Settings", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidCSVSettings
+
+		req := httptest.NewRequest("GET", routePrefix+"/?format=csv&delimiter=,&decimalSeparator=,", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
+
+	t.Run("Invalid_Expansion", func(t *testing.T) {
+		expectedError := apiErrors.ErrInvalidExpansion
+
+		req := httptest.NewRequest("GET", routePrefix+"/?expand=consumer", nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, int(expectedError.Status), res.Code)
+
+		var receivedError types.ServiceError
+		err := json.NewDecoder(res.Body).Decode(&receivedError)
+		assert.NoError(t, err)
+		if t.Failed() {
+			t.FailNow()
+		}
+
+		assert.True(t, receivedError.Equals(expectedError))
+	})
 }
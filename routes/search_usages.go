@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"fmt"
+	"microservice/internal/db"
+	"microservice/structs"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFilenameValues contains the number of values of a single filter
+// dimension which are named in the filename of a downloaded search result
+const maxFilenameValues = 3
+
+func SearchUsages(c *gin.Context) {
+	var query db.UsageQuery
+	if !applyUsageFilter(c, &query) {
+		return
+	}
+	if !applySort(c, &query) {
+		return
+	}
+	if !applyPagination(c, &query) {
+		return
+	}
+
+	sendUsages(c, query, searchFilename(c))
+}
+
+// searchFilename derives the name of a downloaded search result from the
+// filter dimensions in the same way as the routes filtering a single
+// dimension, e.g., usages-consumer-<id>-municipality-<ars>. If a dimension
+// contains many values, only the first ones are named together with the
+// number of omitted values
+func searchFilename(c *gin.Context) string {
+	var filter structs.UsageFilter
+	_ = c.ShouldBindQuery(&filter)
+
+	parts := []string{"usages"}
+	for _, dimension := range []struct {
+		name   string
+		values []string
+	}{
+		{"consumer", filter.ConsumerIDs},
+		{"type", filter.UsageTypeIDs},
+		{"municipality", filter.ARS},
+	} {
+		if len(dimension.values) == 0 {
+			continue
+		}
+
+		var values []string
+		for _, value := range dimension.values[:min(len(dimension.values), maxFilenameValues)] {
+			values = append(values, strings.TrimSpace(value))
+		}
+		if omitted := len(dimension.values) - maxFilenameValues; omitted > 0 {
+			values = append(values, fmt.Sprintf("and_%d_more", omitted))
+		}
+		parts = append(parts, dimension.name, strings.Join(values, "_"))
+	}
+	if len(parts) == 1 {
+		parts = append(parts, "search")
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,117 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	apiErrors "microservice/internal/errors"
+	"microservice/structs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func _search_usages(t *testing.T) {
+	t.Run("Invalid_Consumer_ID", _su_invalid_consumer_id)
+	t.Run("Invalid_ARS", _su_invalid_ars)
+	t.Run("Combined_Filters", _su_combined_filters)
+	t.Run("CSV_Filename", _su_csv_filename)
+}
+
+func _su_invalid_consumer_id(t *testing.T) {
+	apiPath := "search"
+	query := "consumer=not-a-uuid"
+	expectedError := apiErrors.ErrInvalidConsumerID
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _su_invalid_ars(t *testing.T) {
+	apiPath := "search"
+	query := "ars=031515401020&ars=0315"
+	expectedError := apiErrors.ErrInvalidARS
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _su_combined_filters(t *testing.T) {
+	apiPath := "search"
+	consumerID := `390dc645-c0a4-4cdf-8fbd-ab151f8c9687`
+	ars := `031515401020`
+	query := fmt.Sprintf("consumer=%s&ars=%s&from=2020-01-01T00:00:00Z", consumerID, ars)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var entries []structs.UsageRecord
+	err = json.NewDecoder(res.Body).Decode(&entries)
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		assert.Equal(t, consumerID, *entry.ConsumerID)
+		assert.Equal(t, ars, *entry.ARS)
+	}
+}
+
+func _su_csv_filename(t *testing.T) {
+	apiPath := "search"
+	consumerID := `390dc645-c0a4-4cdf-8fbd-ab151f8c9687`
+	query := fmt.Sprintf("consumer=%s&ars=031515401020&ars=03151&ars=03&ars=01&format=csv&expand=municipality", consumerID)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s?%s", routePrefix, apiPath, query), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	expectedFilename := fmt.Sprintf(`"usages-consumer-%s-municipality-031515401020_03151_03_and_1_more.csv"`, consumerID)
+	assert.Contains(t, res.Header().Get("Content-Disposition"), expectedFilename)
+
+	header, _, _ := strings.Cut(res.Body.String(), "\n")
+	assert.Equal(t, "time,amount,usageType,consumerID,ars,municipalityName", header)
+}
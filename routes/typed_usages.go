@@ -1,44 +1,53 @@
 package routes
 
 import (
+	"microservice/internal"
 	"microservice/internal/db"
 	apiErrors "microservice/internal/errors"
-	"microservice/structs"
 	"strings"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 func TypedUsages(c *gin.Context) {
 	usageTypeID := strings.ReplaceAll(strings.TrimSpace(c.Param("usageTypeID")), "/", "")
 
-	if usageTypeID == "" {
+	if serviceErr := internal.ValidateUsageTypeID(usageTypeID); serviceErr != nil {
 		c.Abort()
-		apiErrors.ErrEmptyUsageTypeID.Emit(c)
+		serviceErr.Emit(c)
 		return
 	}
 
-	if err := uuid.Validate(usageTypeID); err != nil {
+	var exists bool
+	q, err := db.Queries.Raw("usage-type-exists")
+	if err != nil {
 		c.Abort()
-		apiErrors.ErrInvalidUsageTypeID.Emit(c)
+		_ = c.Error(err)
 		return
 	}
-
-	q, err := db.Queries.Raw("typed-usages")
+	err = pgxscan.Get(c, db.Pool, &exists, q, usageTypeID)
 	if err != nil {
 		c.Abort()
 		_ = c.Error(err)
 		return
 	}
-	var records []structs.UsageRecord
-	err = pgxscan.Select(c, db.Pool, &records, q, usageTypeID, c.GetInt(KeyPageSize), c.GetInt(KeyPageOffset))
-	if err != nil {
+
+	if !exists {
 		c.Abort()
-		_ = c.Error(err)
+		apiErrors.ErrUnknownUsageType.Emit(c)
+		return
+	}
+
+	var query db.UsageQuery
+	query.Where(db.ColumnUsageType, usageTypeID)
+	applyTimeRange(c, &query)
+	if !applySort(c, &query) {
+		return
+	}
+	if !applyPagination(c, &query) {
 		return
 	}
 
-	c.JSON(200, records)
+	sendUsages(c, query, "usages-type-"+usageTypeID)
 }
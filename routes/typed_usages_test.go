@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/thanhpk/randstr"
 	"github.com/wisdom-oss/common-go/v3/types"
@@ -20,6 +21,7 @@ func _typed_usages(t *testing.T) {
 	t.Parallel()
 	t.Run("Empty_Usage_Type_ID", _tu_empty_usage_type_id)
 	t.Run("Invalid_Usage_Type_ID", _tu_invalid_usage_type_id)
+	t.Run("Unknown_Usage_Type", _tu_unknown_usage_type)
 	t.Run("Valid_Request", _tu_valid_request)
 }
 
@@ -83,6 +85,35 @@ func _tu_invalid_usage_type_id(t *testing.T) {
 
 }
 
+func _tu_unknown_usage_type(t *testing.T) {
+	apiPath := "type"
+	pathParameter := uuid.NewString()
+	expectedError := apiErrors.ErrUnknownUsageType
+
+	t.Parallel()
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
 func _tu_valid_request(t *testing.T) {
 	apiPath := "type"
 	pathParameter := `d9e1dd0b-c25e-45c2-be40-c274b4845ad1`
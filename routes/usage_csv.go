@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"encoding/csv"
+	"fmt"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// usageCSVHeader contains the header line of the CSV output. The column names
+// match the field names of the JSON output
+var usageCSVHeader = []string{"time", "amount", "usageType", "consumerID", "ars"}
+
+// csvMunicipalityNameColumn is the column appended to the CSV output if the
+// names of the municipalities are expanded into the usage records
+const csvMunicipalityNameColumn = "municipalityName"
+
+// csvUsageWriter writes the usage records as CSV file into the response. The
+// delimiter and decimal separator read by routeUtils.ReadOutputSettings are
+// used while writing the records. If fields are selected, only their columns
+// are written. Expanded municipality names are written into an additional
+// last column
+type csvUsageWriter struct {
+	c                  *gin.Context
+	filename           string
+	writer             *csv.Writer
+	decimalSeparator   string
+	fields             []string
+	expandMunicipality bool
+}
+
+func newCSVUsageWriter(c *gin.Context, filename string, fields []string, expandMunicipality bool) *csvUsageWriter {
+	writer := csv.NewWriter(c.Writer)
+	writer.Comma = c.MustGet(KeyCSVDelimiter).(rune)
+
+	return &csvUsageWriter{
+		c:                  c,
+		filename:           filename,
+		writer:             writer,
+		decimalSeparator:   c.GetString(KeyCSVDecimalSeparator),
+		fields:             fields,
+		expandMunicipality: expandMunicipality,
+	}
+}
+
+func (w *csvUsageWriter) WriteHeader() error {
+	w.c.Header("Content-Type", routeUtils.MIMECSV+"; charset=utf-8; header=present")
+	w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, w.filename))
+	w.c.Status(200)
+
+	header := w.project(usageCSVHeader)
+	if w.expandMunicipality {
+		header = append(header, csvMunicipalityNameColumn)
+	}
+	return w.writer.Write(header)
+}
+
+func (w *csvUsageWriter) Write(record structs.UsageRecord) error {
+	row := w.project(usageCSVRow(record, w.decimalSeparator))
+	if w.expandMunicipality {
+		row = append(row, optionalString(record.MunicipalityName))
+	}
+	return w.writer.Write(row)
+}
+
+// project limits a row containing all columns to the columns of the selected
+// fields. As the columns match the order of structs.UsageFields, the column
+// index of a field is its index in structs.UsageFields
+func (w *csvUsageWriter) project(row []string) []string {
+	if len(w.fields) == 0 {
+		return row
+	}
+
+	projection := make([]string, len(w.fields))
+	for i, field := range w.fields {
+		projection[i] = row[slices.Index(structs.UsageFields, field)]
+	}
+	return projection
+}
+
+func (w *csvUsageWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// usageCSVRow converts the usage record into a row of the CSV output. Missing
+// values are written as empty fields
+func usageCSVRow(record structs.UsageRecord, decimalSeparator string) []string {
+	amount := strconv.FormatFloat(record.Amount, 'f', -1, 64)
+	if decimalSeparator != "." {
+		amount = strings.Replace(amount, ".", decimalSeparator, 1)
+	}
+
+	return []string{
+		record.Time.Time.Format(time.RFC3339),
+		amount,
+		optionalString(record.UsageType),
+		optionalString(record.ConsumerID),
+		optionalString(record.ARS),
+	}
+}
+
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
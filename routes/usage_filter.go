@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"errors"
+	"microservice/internal"
+	"microservice/internal/db"
+	apiErrors "microservice/internal/errors"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// applyUsageFilter reads the filter dimensions and the time range from the
+// request, validates them and adds them to the query. If the request contains
+// an invalid filter value, the matching error is emitted, the request is
+// aborted and false is returned
+func applyUsageFilter(c *gin.Context, query *db.UsageQuery) bool {
+	var filter structs.UsageFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return false
+	}
+
+	if len(filter.ConsumerIDs) > 0 {
+		var consumerIDs []uuid.UUID
+		for _, consumerID := range filter.ConsumerIDs {
+			consumerID = strings.TrimSpace(consumerID)
+			if serviceErr := internal.ValidateConsumerID(consumerID); serviceErr != nil {
+				c.Abort()
+				serviceErr.Emit(c)
+				return false
+			}
+			consumerIDs = append(consumerIDs, uuid.MustParse(consumerID))
+		}
+		query.WhereIn(db.ColumnConsumer, consumerIDs)
+	}
+
+	if len(filter.UsageTypeIDs) > 0 {
+		var usageTypeIDs []uuid.UUID
+		for _, usageTypeID := range filter.UsageTypeIDs {
+			usageTypeID = strings.TrimSpace(usageTypeID)
+			if serviceErr := internal.ValidateUsageTypeID(usageTypeID); serviceErr != nil {
+				c.Abort()
+				serviceErr.Emit(c)
+				return false
+			}
+			usageTypeIDs = append(usageTypeIDs, uuid.MustParse(usageTypeID))
+		}
+		query.WhereIn(db.ColumnUsageType, usageTypeIDs)
+	}
+
+	if len(filter.ARS) > 0 {
+		var municipalities []string
+		for _, ars := range filter.ARS {
+			ars = strings.TrimSpace(ars)
+			if serviceErr := internal.ValidateARS(ars); serviceErr != nil {
+				c.Abort()
+				serviceErr.Emit(c)
+				return false
+			}
+			municipalities = append(municipalities, ars)
+		}
+		query.WhereIn(db.ColumnMunicipality, municipalities)
+	}
+
+	applyTimeRange(c, query)
+	return true
+}
+
+// applySort reads the requested sort order of the usage records and adds it to
+// the query. If the sort order contains an invalid field, the error is emitted,
+// the request is aborted and false is returned
+func applySort(c *gin.Context, query *db.UsageQuery) bool {
+	var settings structs.SortSettings
+	err := c.ShouldBindQuery(&settings)
+
+	var fields []structs.SortField
+	if err == nil {
+		fields, err = structs.ParseSort(settings.Sort, structs.UsageFields)
+	}
+	if err != nil {
+		serviceErr := apiErrors.ErrInvalidSortField
+		serviceErr.Errors = []error{err}
+		c.Abort()
+		serviceErr.Emit(c)
+		return false
+	}
+
+	query.OrderBy(fields)
+	return true
+}
+
+// applyTimeRange adds the time range read by routeUtils.ReadTimeRange to the
+// query
+func applyTimeRange(c *gin.Context, query *db.UsageQuery) {
+	if from, isSet := c.Get(KeyTimeRangeFrom); isSet {
+		query.From(from)
+	}
+	if until, isSet := c.Get(KeyTimeRangeUntil); isSet {
+		query.Until(until)
+	}
+}
+
+// applyPagination adds the page settings read by routeUtils.ReadPageSettings
+// to the query. If the request contains a cursor, the query continues after
+// the usage record the cursor points to. The sort order needs to be applied
+// before, as a cursor created for another sort order is rejected by emitting
+// the error, aborting the request and returning false.
+// Streamed NDJSON and Parquet responses are only paginated if the request
+// explicitly contains pagination parameters, as they are meant for bulk
+// exports
+func applyPagination(c *gin.Context, query *db.UsageQuery) bool {
+	switch c.GetString(KeyOutputFormat) {
+	case routeUtils.FormatNDJSON, routeUtils.FormatParquet:
+		if !hasPaginationParameters(c) {
+			return true
+		}
+	}
+
+	if value, isSet := c.Get(KeyPageCursor); isSet {
+		cursor := value.(structs.UsageCursor)
+		if cursor.Sort != structs.FormatSort(query.SortOrder()) {
+			serviceErr := apiErrors.ErrInvalidCursor
+			serviceErr.Errors = []error{errors.New("the cursor has been created for another sort order")}
+			c.Abort()
+			serviceErr.Emit(c)
+			return false
+		}
+		query.After(cursor)
+	}
+	query.Paginate(c.GetInt(KeyPageSize), c.GetInt(KeyPageOffset))
+	return true
+}
+
+// hasPaginationParameters reports if the request contains any query parameter
+// controlling the pagination
+func hasPaginationParameters(c *gin.Context) bool {
+	query := c.Request.URL.Query()
+	return query.Has("page") || query.Has("size") || query.Has("pageSize") || query.Has("cursor")
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"encoding/json"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ndjsonUsageWriter writes every usage record as a single JSON document
+// followed by a newline into the response. The response is flushed after
+// each usage record to allow the client to process the records while they
+// are received. If fields are selected, the usage records are limited to them
+type ndjsonUsageWriter struct {
+	c       *gin.Context
+	encoder *json.Encoder
+	fields  []string
+}
+
+func newNDJSONUsageWriter(c *gin.Context, fields []string) *ndjsonUsageWriter {
+	return &ndjsonUsageWriter{
+		c:       c,
+		encoder: json.NewEncoder(c.Writer),
+		fields:  fields,
+	}
+}
+
+func (w *ndjsonUsageWriter) WriteHeader() error {
+	w.c.Header("Content-Type", routeUtils.MIMENDJSON)
+	w.c.Status(200)
+	w.c.Writer.WriteHeaderNow()
+	return nil
+}
+
+func (w *ndjsonUsageWriter) Write(record structs.UsageRecord) error {
+	var document any = record
+	if len(w.fields) > 0 {
+		document = usageProjection{record: record, fields: w.fields}
+	}
+	if err := w.encoder.Encode(document); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+func (w *ndjsonUsageWriter) Close() error {
+	w.c.Writer.Flush()
+	return nil
+}
@@ -0,0 +1,90 @@
+package routes
+
+import (
+	"fmt"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	// parquetRowGroupSize contains the number of usage records written into a
+	// single row group of the parquet file. Only a single row group is kept
+	// in memory while writing the file
+	parquetRowGroupSize = 50000
+
+	// parquetBatchSize contains the number of usage records handed to the
+	// parquet writer at once
+	parquetBatchSize = 1000
+)
+
+// usageParquetRow contains the layout of a usage record in the parquet
+// file. The column names match the field names of the JSON output
+type usageParquetRow struct {
+	Time       time.Time `parquet:"time,timestamp(microsecond:utc)"`
+	Amount     float64   `parquet:"amount"`
+	UsageType  *string   `parquet:"usageType,optional"`
+	ConsumerID *string   `parquet:"consumerID,optional"`
+	ARS        *string   `parquet:"ars,optional"`
+}
+
+// parquetUsageWriter writes the usage records as Apache Parquet file into the
+// response. The row groups are written into the response as soon as they
+// have been filled
+type parquetUsageWriter struct {
+	c        *gin.Context
+	filename string
+	writer   *parquet.GenericWriter[usageParquetRow]
+	batch    []usageParquetRow
+}
+
+func newParquetUsageWriter(c *gin.Context, filename string) *parquetUsageWriter {
+	return &parquetUsageWriter{
+		c:        c,
+		filename: filename,
+		batch:    make([]usageParquetRow, 0, parquetBatchSize),
+	}
+}
+
+func (w *parquetUsageWriter) WriteHeader() error {
+	w.c.Header("Content-Type", routeUtils.MIMEParquet)
+	w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.parquet"`, w.filename))
+	w.c.Status(200)
+
+	w.writer = parquet.NewGenericWriter[usageParquetRow](w.c.Writer,
+		parquet.Compression(&parquet.Snappy),
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+	)
+	return nil
+}
+
+func (w *parquetUsageWriter) Write(record structs.UsageRecord) error {
+	w.batch = append(w.batch, usageParquetRow{
+		Time:       record.Time.Time,
+		Amount:     record.Amount,
+		UsageType:  record.UsageType,
+		ConsumerID: record.ConsumerID,
+		ARS:        record.ARS,
+	})
+	if len(w.batch) < parquetBatchSize {
+		return nil
+	}
+	return w.writeBatch()
+}
+
+func (w *parquetUsageWriter) Close() error {
+	if err := w.writeBatch(); err != nil {
+		return err
+	}
+	return w.writer.Close()
+}
+
+// writeBatch hands the buffered usage records to the parquet writer
+func (w *parquetUsageWriter) writeBatch() error {
+	_, err := w.writer.Write(w.batch)
+	w.batch = w.batch[:0]
+	return err
+}
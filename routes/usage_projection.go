@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"microservice/structs"
+)
+
+// usageProjection limits the JSON representation of a usage record to the
+// requested fields. The fields are written in the order of the full usage
+// record. The name of the municipality is kept if it has been expanded
+type usageProjection struct {
+	record structs.UsageRecord
+	fields []string
+}
+
+func (p usageProjection) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for _, field := range p.fields {
+		if err := writeJSONMember(&buffer, field, usageFieldValue(p.record, field)); err != nil {
+			return nil, err
+		}
+	}
+	if p.record.MunicipalityName != nil {
+		if err := writeJSONMember(&buffer, "municipalityName", p.record.MunicipalityName); err != nil {
+			return nil, err
+		}
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// writeJSONMember writes the name and value of a single object member into the
+// buffer which already contains the opening brace of the object. Members
+// following the first member are separated by a comma
+func writeJSONMember(buffer *bytes.Buffer, name string, value any) error {
+	if buffer.Len() > 1 {
+		buffer.WriteByte(',')
+	}
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	buffer.WriteString(`"` + name + `":`)
+	buffer.Write(encodedValue)
+	return nil
+}
+
+// usageFieldValue returns the value of the field of the usage record. The
+// field needs to be one of structs.UsageFields
+func usageFieldValue(record structs.UsageRecord, field string) any {
+	switch field {
+	case "time":
+		return record.Time
+	case "amount":
+		return record.Amount
+	case "usageType":
+		return record.UsageType
+	case "consumerID":
+		return record.ConsumerID
+	default:
+		return record.ARS
+	}
+}
+
+// projectUsages limits the usage records to the requested fields. If no fields
+// have been requested, the usage records are returned unchanged
+func projectUsages(records []structs.UsageRecord, fields []string) any {
+	if len(fields) == 0 {
+		return records
+	}
+
+	projections := make([]usageProjection, len(records))
+	for i, record := range records {
+		projections[i] = usageProjection{record: record, fields: fields}
+	}
+	return projections
+}
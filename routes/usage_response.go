@@ -0,0 +1,201 @@
+package routes
+
+import (
+	"microservice/internal/db"
+	routeUtils "microservice/routes/utils"
+	"microservice/structs"
+	"strconv"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/gin-gonic/gin"
+)
+
+// sendUsages executes the query and sends the usage records as response in
+// the output format read by routeUtils.ReadOutputSettings. The filename is
+// used as name of the file if the output format is downloaded as a file.
+//
+// If requested, the total number of matched usage records is sent in the
+// HeaderTotalCount header. Paginated responses also contain the links to the
+// surrounding pages in the Link header. If requested, the usage records are
+// limited to the selected fields and the names of the municipalities are
+// expanded into the usage records.
+// Conditional requests are answered before the usage records are queried.
+func sendUsages(c *gin.Context, query db.UsageQuery, filename string) {
+	query.Select(c.GetStringSlice(KeyFields))
+
+	if c.GetBool(KeyExpandMunicipality) {
+		query.ExpandMunicipality()
+	}
+
+	if answerConditional(c) {
+		return
+	}
+
+	var total *int64
+	if c.GetBool(KeyPageCount) {
+		q, args := query.BuildCount()
+
+		var count int64
+		err := db.CachedGet(c, &count, q, args...)
+		if err != nil {
+			c.Abort()
+			_ = c.Error(err)
+			return
+		}
+		total = &count
+		c.Header(HeaderTotalCount, strconv.FormatInt(count, 10))
+	}
+
+	switch c.GetString(KeyOutputFormat) {
+	case routeUtils.FormatCSV:
+		streamUsages(c, query, total, newCSVUsageWriter(c, filename, query.Fields(), c.GetBool(KeyExpandMunicipality)))
+	case routeUtils.FormatNDJSON:
+		streamUsages(c, query, total, newNDJSONUsageWriter(c, query.Fields()))
+	case routeUtils.FormatParquet:
+		streamUsages(c, query, total, newParquetUsageWriter(c, filename))
+	default:
+		sendUsagesJSON(c, query, total)
+	}
+}
+
+// sendUsagesJSON sends the usage records as a single JSON array. If the page
+// has been filled completely, the cursor pointing to the next page is sent in
+// the HeaderNextCursor header. The usage records are read from the db.Cache
+// if the same page has already been queried
+func sendUsagesJSON(c *gin.Context, query db.UsageQuery, total *int64) {
+	query.SelectCursor()
+	q, args := query.Build()
+
+	var records []structs.UsageRecord
+	err := db.CachedSelect(c, &records, q, args...)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var nextCursor string
+	if len(records) > 0 && len(records) == c.GetInt(KeyPageSize) && records[len(records)-1].Cursor != nil {
+		// the records are shared with the cache and must not be modified
+		cursor := *records[len(records)-1].Cursor
+		cursor.Sort = structs.FormatSort(query.SortOrder())
+		nextCursor, err = routeUtils.EncodeCursor(cursor)
+		if err != nil {
+			c.Abort()
+			_ = c.Error(err)
+			return
+		}
+		c.Header(HeaderNextCursor, nextCursor)
+	}
+
+	links := pageLinks(c, nextCursor, total)
+	c.Header("Link", routeUtils.FormatLinks(links))
+	sendJSON(c, projectUsages(records, query.Fields()), usageMeta(pageMeta(c, total)), links)
+}
+
+// usageWriter is implemented by the output formats which write the usage
+// records into the response while they are read from the database
+type usageWriter interface {
+	// WriteHeader sets the response headers and writes everything preceding
+	// the first usage record
+	WriteHeader() error
+
+	// Write writes a single usage record into the response
+	Write(record structs.UsageRecord) error
+
+	// Close writes everything following the last usage record and flushes
+	// the response
+	Close() error
+}
+
+// streamUsages iterates over the rows returned by the query and writes each
+// usage record into the response as soon as it has been read. This keeps the
+// memory usage constant regardless of the number of usage records.
+// As the headers are sent before the last usage record is known, streamed
+// responses do not contain the HeaderNextCursor header and are not cached
+func streamUsages(c *gin.Context, query db.UsageQuery, total *int64, writer usageWriter) {
+	q, args := query.Build()
+
+	rows, err := db.Pool.Query(c, q, args...)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+	defer rows.Close()
+
+	// read the first row before sending the headers to be able to respond
+	// with an error if the query failed
+	hasRows := rows.Next()
+	if err := rows.Err(); err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	if query.Paginated() {
+		c.Header("Link", routeUtils.FormatLinks(pageLinks(c, "", total)))
+	}
+
+	if err := writer.WriteHeader(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	scanner := pgxscan.NewRowScanner(rows)
+	for ; hasRows; hasRows = rows.Next() {
+		var record structs.UsageRecord
+		if err := scanner.Scan(&record); err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if err := writer.Write(record); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// pageLinks generates the links to the first, previous, next and last page
+// of the request. The previous and last page are only available if the
+// request uses the page numbers for the pagination and the last page requires
+// the total count of the matched usage records
+func pageLinks(c *gin.Context, nextCursor string, total *int64) []routeUtils.Link {
+	query := c.Request.URL.Query()
+	pageSize := c.GetInt(KeyPageSize)
+	page := c.GetInt(KeyPageOffset)/pageSize + 1
+	_, usesCursor := c.Get(KeyPageCursor)
+
+	links := []routeUtils.Link{
+		{Target: routeUtils.PageTarget(query, 1), Rel: "first"},
+	}
+
+	if !usesCursor && page > 1 {
+		links = append(links, routeUtils.Link{Target: routeUtils.PageTarget(query, page-1), Rel: "prev"})
+	}
+
+	switch {
+	case usesCursor && nextCursor != "":
+		links = append(links, routeUtils.Link{Target: routeUtils.CursorTarget(query, nextCursor), Rel: "next"})
+	case !usesCursor && total != nil && int64(page*pageSize) < *total:
+		links = append(links, routeUtils.Link{Target: routeUtils.PageTarget(query, page+1), Rel: "next"})
+	case !usesCursor && total == nil && nextCursor != "":
+		links = append(links, routeUtils.Link{Target: routeUtils.PageTarget(query, page+1), Rel: "next"})
+	}
+
+	if !usesCursor && total != nil {
+		lastPage := int((*total + int64(pageSize) - 1) / int64(pageSize))
+		links = append(links, routeUtils.Link{Target: routeUtils.PageTarget(query, max(lastPage, 1)), Rel: "last"})
+	}
+
+	return links
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"microservice/internal"
+	"microservice/internal/db"
+	apiErrors "microservice/internal/errors"
+	"microservice/structs"
+	"strings"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/gin-gonic/gin"
+)
+
+func UsageTypes(c *gin.Context) {
+	q, err := db.Queries.Raw("get-usage-types")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var usageTypes []structs.UsageType
+	err = pgxscan.Select(c, db.Pool, &usageTypes, q)
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	sendJSON(c, usageTypes, structs.EnvelopeMeta{}, nil)
+}
+
+func UsageType(c *gin.Context) {
+	usageTypeID := strings.TrimSpace(c.Param("usageTypeID"))
+
+	if serviceErr := internal.ValidateUsageTypeID(usageTypeID); serviceErr != nil {
+		c.Abort()
+		serviceErr.Emit(c)
+		return
+	}
+
+	q, err := db.Queries.Raw("get-usage-type")
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	var usageType structs.UsageType
+	err = pgxscan.Get(c, db.Pool, &usageType, q, usageTypeID)
+	if pgxscan.NotFound(err) {
+		c.Abort()
+		apiErrors.ErrUnknownUsageType.Emit(c)
+		return
+	}
+	if err != nil {
+		c.Abort()
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(200, usageType)
+}
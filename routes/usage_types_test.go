@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	apiErrors "microservice/internal/errors"
+	"microservice/structs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/wisdom-oss/common-go/v3/types"
+)
+
+func _usage_types(t *testing.T) {
+	t.Run("List", _ut_list)
+	t.Run("Invalid_Usage_Type_ID", _ut_invalid_usage_type_id)
+	t.Run("Unknown_Usage_Type", _ut_unknown_usage_type)
+}
+
+func _ut_list(t *testing.T) {
+	apiPath := "types"
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s", routePrefix, apiPath), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var usageTypes []structs.UsageType
+	err = json.NewDecoder(res.Body).Decode(&usageTypes)
+	assert.NoError(t, err)
+
+	// every usage type in the list needs to be retrievable on its own
+	for _, usageType := range usageTypes {
+		req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, usageType.ID), nil)
+		res := httptest.NewRecorder()
+
+		r.Handler().ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		var receivedUsageType structs.UsageType
+		err = json.NewDecoder(res.Body).Decode(&receivedUsageType)
+		assert.NoError(t, err)
+		assert.Equal(t, usageType, receivedUsageType)
+	}
+}
+
+func _ut_invalid_usage_type_id(t *testing.T) {
+	apiPath := "types"
+	pathParameter := "not-a-uuid"
+	expectedError := apiErrors.ErrInvalidUsageTypeID
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	var receivedError types.ServiceError
+	err := json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
+
+func _ut_unknown_usage_type(t *testing.T) {
+	apiPath := "types"
+	pathParameter := uuid.NewString()
+	expectedError := apiErrors.ErrUnknownUsageType
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("%s/%s/%s", routePrefix, apiPath, pathParameter), nil)
+	res := httptest.NewRecorder()
+
+	r.Handler().ServeHTTP(res, req)
+	assert.Equal(t, int(expectedError.Status), res.Code)
+
+	err := openapi3filter.ValidateResponse(context.Background(), generateValidationData(t, req, res))
+	if err != nil {
+		t.Fail()
+		t.Log(err)
+	}
+
+	var receivedError types.ServiceError
+	err = json.NewDecoder(res.Body).Decode(&receivedError)
+	assert.NoError(t, err)
+	if t.Failed() {
+		t.FailNow()
+	}
+
+	assert.True(t, receivedError.Equals(expectedError))
+}
@@ -0,0 +1,30 @@
+package routeUtils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"microservice/structs"
+)
+
+// EncodeCursor creates the opaque cursor from the sort key of the last usage
+// record of a page
+func EncodeCursor(cursor structs.UsageCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// DecodeCursor parses an opaque cursor created by EncodeCursor
+func DecodeCursor(rawCursor string) (structs.UsageCursor, error) {
+	var cursor structs.UsageCursor
+
+	payload, err := base64.RawURLEncoding.DecodeString(rawCursor)
+	if err != nil {
+		return cursor, err
+	}
+
+	err = json.Unmarshal(payload, &cursor)
+	return cursor, err
+}
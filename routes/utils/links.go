@@ -0,0 +1,57 @@
+package routeUtils
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Link is a single web link as described in RFC 8288
+type Link struct {
+	Target string
+	Rel    string
+}
+
+// String formats the link as it is used in a Link header
+func (l Link) String() string {
+	return fmt.Sprintf(`<%s>; rel="%s"`, l.Target, l.Rel)
+}
+
+// FormatLinks joins the links into the value of a single Link header
+func FormatLinks(links []Link) string {
+	formattedLinks := make([]string, len(links))
+	for i, link := range links {
+		formattedLinks[i] = link.String()
+	}
+	return strings.Join(formattedLinks, ", ")
+}
+
+// PageTarget creates the link target for another page of the request. The
+// target only consists of the query of the request, as the service is
+// usually reachable under a different path behind the gateway. All query
+// parameters not related to the pagination are kept as they are
+func PageTarget(query url.Values, page int) string {
+	query = cloneValues(query)
+	query.Del("cursor")
+	query.Set("page", strconv.Itoa(page))
+	return "?" + query.Encode()
+}
+
+// CursorTarget creates the link target continuing the request after the
+// cursor. All query parameters not related to the pagination are kept as they
+// are
+func CursorTarget(query url.Values, cursor string) string {
+	query = cloneValues(query)
+	query.Del("page")
+	query.Set("cursor", cursor)
+	return "?" + query.Encode()
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
@@ -0,0 +1,155 @@
+package routeUtils
+
+import (
+	"errors"
+	"microservice/structs"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "microservice/internal/errors"
+)
+
+const (
+	KeyOutputFormat        = "output.format"
+	KeyCSVDelimiter        = "output.csv.delimiter"
+	KeyCSVDecimalSeparator = "output.csv.decimal-separator"
+	KeyFields              = "output.fields"
+	KeyExpandMunicipality  = "output.expand.municipality"
+	KeyEnvelope            = "output.envelope"
+)
+
+// EnvelopeProfile is the profile of the JSON media type which requests JSON
+// responses to be wrapped into an envelope containing metadata about the
+// response
+const EnvelopeProfile = "urn:wisdom:usage-history:envelope"
+
+const (
+	FormatJSON    = "json"
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+const (
+	MIMEJSON    = "application/json"
+	MIMECSV     = "text/csv"
+	MIMENDJSON  = "application/x-ndjson"
+	MIMEParquet = "application/vnd.apache.parquet"
+)
+
+// ReadOutputSettings determines the output format of the response. The format
+// may be set explicitly using the `format` query parameter which takes
+// precedence over the `Accept` header. If neither requests a supported format,
+// the response is sent as JSON.
+// Furthermore, the delimiter and decimal separator used in CSV responses are
+// read and validated, as well as the selected fields and the references which
+// are expanded into the usage records. Parquet files always contain every
+// field as their schema is fixed, therefore fields may not be selected and
+// references may not be expanded for them.
+// JSON responses are wrapped into an envelope if requested by the `envelope`
+// query parameter or the EnvelopeProfile in the `Accept` header
+func ReadOutputSettings(c *gin.Context) {
+	var outputSettings structs.OutputSettings
+
+	err := c.ShouldBindQuery(&outputSettings)
+	if err != nil {
+		c.Abort()
+		if slices.ContainsFunc(outputSettings.Expand, func(expansion string) bool { return expansion != "municipality" }) {
+			apiErrors.ErrInvalidExpansion.Emit(c)
+			return
+		}
+		if _, err := strconv.ParseBool(c.DefaultQuery("envelope", "false")); err != nil {
+			apiErrors.ErrInvalidEnvelope.Emit(c)
+			return
+		}
+		apiErrors.ErrUnsupportedFormat.Emit(c)
+		return
+	}
+
+	if outputSettings.Format == "" {
+		switch c.NegotiateFormat(MIMEJSON, MIMECSV, MIMENDJSON, MIMEParquet) {
+		case MIMECSV:
+			outputSettings.Format = FormatCSV
+		case MIMENDJSON:
+			outputSettings.Format = FormatNDJSON
+		case MIMEParquet:
+			outputSettings.Format = FormatParquet
+		default:
+			outputSettings.Format = FormatJSON
+		}
+	}
+
+	if outputSettings.Delimiter == "" {
+		outputSettings.Delimiter = ","
+	}
+	if outputSettings.DecimalSeparator == "" {
+		outputSettings.DecimalSeparator = "."
+	}
+
+	delimiter, _ := utf8.DecodeRuneInString(outputSettings.Delimiter)
+	if utf8.RuneCountInString(outputSettings.Delimiter) != 1 || delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		c.Abort()
+		apiErrors.ErrInvalidCSVSettings.Emit(c)
+		return
+	}
+
+	if outputSettings.DecimalSeparator != "." && outputSettings.DecimalSeparator != "," {
+		c.Abort()
+		apiErrors.ErrInvalidCSVSettings.Emit(c)
+		return
+	}
+
+	if outputSettings.Delimiter == outputSettings.DecimalSeparator {
+		c.Abort()
+		apiErrors.ErrInvalidCSVSettings.Emit(c)
+		return
+	}
+
+	if len(outputSettings.Expand) > 0 && outputSettings.Format == FormatParquet {
+		serviceErr := apiErrors.ErrInvalidExpansion
+		serviceErr.Errors = []error{errors.New("references can't be expanded for parquet files")}
+		c.Abort()
+		serviceErr.Emit(c)
+		return
+	}
+
+	fields, err := structs.ParseFields(outputSettings.Fields)
+	if err == nil && len(fields) > 0 && outputSettings.Format == FormatParquet {
+		err = errors.New("fields can't be selected for parquet files")
+	}
+	if err != nil {
+		serviceErr := apiErrors.ErrInvalidFields
+		serviceErr.Errors = []error{err}
+		c.Abort()
+		serviceErr.Emit(c)
+		return
+	}
+
+	c.Set(KeyOutputFormat, outputSettings.Format)
+	c.Set(KeyCSVDelimiter, delimiter)
+	c.Set(KeyCSVDecimalSeparator, outputSettings.DecimalSeparator)
+	c.Set(KeyFields, fields)
+	c.Set(KeyExpandMunicipality, slices.Contains(outputSettings.Expand, "municipality"))
+	c.Set(KeyEnvelope, outputSettings.Envelope || acceptsEnvelope(c.GetHeader("Accept")))
+}
+
+// acceptsEnvelope reports if the Accept header contains a JSON media range
+// requesting the EnvelopeProfile. The profile parameter may contain multiple
+// space separated profiles as described in RFC 6906
+func acceptsEnvelope(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, parameters, err := mime.ParseMediaType(mediaRange)
+		if err != nil || mediaType != MIMEJSON {
+			continue
+		}
+		if slices.Contains(strings.Fields(parameters["profile"]), EnvelopeProfile) {
+			return true
+		}
+	}
+	return false
+}
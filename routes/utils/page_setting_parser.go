@@ -5,26 +5,73 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"microservice/internal/config"
 	apiErrors "microservice/internal/errors"
 )
 
 const (
 	KeyPageOffset = "query.offset"
 	KeyPageSize   = "query.page-size"
+	KeyPageCursor = "query.cursor"
+	KeyPageCount  = "query.count"
 )
 
 func ReadPageSettings(c *gin.Context) {
 	var pageSettings structs.PageSettings
 
-	err := c.ShouldBind(&pageSettings)
+	err := c.ShouldBindQuery(&pageSettings)
 	if err != nil {
 		c.Abort()
 		apiErrors.ErrInvalidPageSettings.Emit(c)
 		return
 	}
 
-	offset := pageSettings.Size * (pageSettings.Page - 1)
+	pageSize, valid := resolvePageSize(pageSettings, config.Current.Pagination.Limits(c.FullPath()))
+	if !valid {
+		c.Abort()
+		apiErrors.ErrInvalidPageSettings.Emit(c)
+		return
+	}
+
+	// a cursor already points to the start of the requested page, therefore
+	// it can't be combined with a page number
+	if pageSettings.Cursor != "" {
+		if pageSettings.Page != 1 {
+			c.Abort()
+			apiErrors.ErrInvalidPageSettings.Emit(c)
+			return
+		}
+
+		cursor, err := DecodeCursor(pageSettings.Cursor)
+		if err != nil {
+			c.Abort()
+			apiErrors.ErrInvalidCursor.Emit(c)
+			return
+		}
+		c.Set(KeyPageCursor, cursor)
+	}
+
+	offset := pageSize * (pageSettings.Page - 1)
 
 	c.Set(KeyPageOffset, offset)
-	c.Set(KeyPageSize, pageSettings.Size)
+	c.Set(KeyPageSize, pageSize)
+	c.Set(KeyPageCount, pageSettings.Count)
+}
+
+// resolvePageSize returns the page size requested using either of the accepted
+// parameter names or the default page size of the limits. If both names are
+// supplied with different sizes or the size exceeds the maximum page size of
+// the limits, the page size is not valid
+func resolvePageSize(pageSettings structs.PageSettings, limits config.PageLimits) (int, bool) {
+	size := pageSettings.Size
+	if size == nil {
+		size = pageSettings.LegacySize
+	} else if pageSettings.LegacySize != nil && *pageSettings.LegacySize != *size {
+		return 0, false
+	}
+
+	if size == nil {
+		return limits.DefaultPageSize, true
+	}
+	return *size, *size <= limits.MaxPageSize
 }
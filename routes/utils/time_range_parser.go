@@ -0,0 +1,50 @@
+package routeUtils
+
+import (
+	"microservice/structs"
+
+	"github.com/gin-gonic/gin"
+
+	apiErrors "microservice/internal/errors"
+)
+
+const (
+	KeyTimeRangeFrom  = "query.from"
+	KeyTimeRangeUntil = "query.until"
+)
+
+// ReadTimeRange parses the optional `from` and `until` query parameters and
+// stores the parsed timestamps in the context. A bound that has not been set
+// is not stored in the context which allows the queries to handle it as an
+// open end of the range
+func ReadTimeRange(c *gin.Context) {
+	var timeRange structs.TimeRange
+
+	err := c.ShouldBindQuery(&timeRange)
+	if err != nil {
+		c.Abort()
+		apiErrors.ErrInvalidTimestamp.Emit(c)
+		return
+	}
+
+	if timeRange.From != nil && timeRange.From.IsZero() {
+		timeRange.From = nil
+	}
+
+	if timeRange.Until != nil && timeRange.Until.IsZero() {
+		timeRange.Until = nil
+	}
+
+	if timeRange.From != nil && timeRange.Until != nil && !timeRange.From.Before(*timeRange.Until) {
+		c.Abort()
+		apiErrors.ErrInvertedTimeRange.Emit(c)
+		return
+	}
+
+	if timeRange.From != nil {
+		c.Set(KeyTimeRangeFrom, *timeRange.From)
+	}
+	if timeRange.Until != nil {
+		c.Set(KeyTimeRangeUntil, *timeRange.Until)
+	}
+}
@@ -0,0 +1,14 @@
+package structs
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// AggregatedUsage contains the aggregated usage amounts of a single time
+// bucket
+type AggregatedUsage struct {
+	Bucket  pgtype.Timestamptz `json:"bucket" db:"bucket"`
+	Sum     float64            `json:"sum" db:"sum"`
+	Average float64            `json:"average" db:"average"`
+	Minimum float64            `json:"minimum" db:"minimum"`
+	Maximum float64            `json:"maximum" db:"maximum"`
+	Count   int64              `json:"count" db:"count"`
+}
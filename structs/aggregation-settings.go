@@ -0,0 +1,12 @@
+package structs
+
+// the time zone database is embedded as the container image does not contain
+// one, which is needed to validate the time zones
+import _ "time/tzdata"
+
+// AggregationSettings contains the size of the time buckets the usage amounts
+// are aggregated into and the time zone the buckets start in
+type AggregationSettings struct {
+	Bucket   string `form:"bucket,default=month" binding:"oneof=day week month quarter year"`
+	TimeZone string `form:"tz,default=Europe/Berlin" binding:"timezone"`
+}
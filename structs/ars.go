@@ -0,0 +1,125 @@
+package structs
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ARSLevels contains the lengths of the ARS prefixes identifying the regional
+// levels of the ARS: Land, Regierungsbezirk, Kreis, Gemeindeverband and
+// Gemeinde
+var ARSLevels = []int{2, 3, 5, 9, 12}
+
+// arsComponents contains the names of the ARS components in the order of the
+// regional levels
+var arsComponents = []string{"Land", "Regierungsbezirk", "Kreis", "Gemeindeverband", "Gemeinde"}
+
+// ARS contains the components of an Amtlicher Regionalschlüssel which
+// identifies a region in Germany. An ARS may be shortened to a prefix
+// identifying a region on a higher regional level. In this case, the
+// components below this level are empty
+type ARS struct {
+	Land             string
+	Regierungsbezirk string
+	Kreis            string
+	Gemeindeverband  string
+	Gemeinde         string
+}
+
+// ARSError describes the component of an ARS which made the ARS invalid. If
+// the ARS has an invalid length, the component is empty
+type ARSError struct {
+	Component string
+	Value     string
+	Reason    string
+}
+
+func (e ARSError) Error() string {
+	if e.Component == "" {
+		return fmt.Sprintf("invalid ARS '%s': %s", e.Value, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s code '%s': %s", e.Component, e.Value, e.Reason)
+}
+
+// ParseARS splits the ARS or ARS prefix into its components. The ARS needs to
+// contain only digits and the Land code needs to identify one of the 16 German
+// states. If the ARS is not valid, an ARSError is returned
+func ParseARS(value string) (ARS, error) {
+	if !slices.Contains(ARSLevels, len(value)) {
+		return ARS{}, ARSError{Value: value, Reason: "needs to contain 2, 3, 5, 9 or 12 digits"}
+	}
+
+	var components [5]string
+	start := 0
+	for index, end := range ARSLevels {
+		if end > len(value) {
+			break
+		}
+		component := value[start:end]
+		if strings.Trim(component, "0123456789") != "" {
+			return ARS{}, ARSError{Component: arsComponents[index], Value: component, Reason: "may only contain digits"}
+		}
+		components[index] = component
+		start = end
+	}
+
+	land, _ := strconv.Atoi(components[0])
+	if land < 1 || land > 16 {
+		return ARS{}, ARSError{Component: arsComponents[0], Value: components[0], Reason: "needs to be between 01 and 16"}
+	}
+
+	return ARS{
+		Land:             components[0],
+		Regierungsbezirk: components[1],
+		Kreis:            components[2],
+		Gemeindeverband:  components[3],
+		Gemeinde:         components[4],
+	}, nil
+}
+
+// MustParseARS is like ParseARS but panics if the ARS is not valid. It should
+// only be used on already validated values
+func MustParseARS(value string) ARS {
+	ars, err := ParseARS(value)
+	if err != nil {
+		panic(err)
+	}
+	return ars
+}
+
+// String joins the components back into the ARS
+func (a ARS) String() string {
+	return a.Land + a.Regierungsbezirk + a.Kreis + a.Gemeindeverband + a.Gemeinde
+}
+
+// Municipal reports if the ARS identifies a single municipality instead of a
+// region containing multiple municipalities
+func (a ARS) Municipal() bool {
+	return a.Gemeinde != ""
+}
+
+// ChildLength returns the length of the ARS prefixes identifying the regions
+// directly below the region. A municipality has no child regions, therefore
+// the length of its own ARS is returned
+func (a ARS) ChildLength() int {
+	length := len(a.String())
+	for _, level := range ARSLevels {
+		if level > length {
+			return level
+		}
+	}
+	return length
+}
+
+// Level returns the name of the lowest regional level contained in the ARS
+func (a ARS) Level() string {
+	length := len(a.String())
+	for index, level := range ARSLevels {
+		if level == length {
+			return arsComponents[index]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,16 @@
+package structs
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// ConsumerSummary contains an overview of the usages recorded for a single
+// consumer. The dominant usage type is the usage type with the largest total
+// volume
+type ConsumerSummary struct {
+	ConsumerID        string             `json:"consumerID" db:"-"`
+	FirstReading      pgtype.Timestamptz `json:"firstReading" db:"first_reading"`
+	LastReading       pgtype.Timestamptz `json:"lastReading" db:"last_reading"`
+	TotalAmount       float64            `json:"totalAmount" db:"total_amount"`
+	Readings          int64              `json:"readings" db:"readings"`
+	DominantUsageType *string            `json:"dominantUsageType" db:"dominant_usage_type"`
+	Municipalities    []string           `json:"municipalities" db:"municipalities"`
+}
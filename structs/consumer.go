@@ -0,0 +1,12 @@
+package structs
+
+// Consumer contains a consumer the usages may be recorded for
+type Consumer struct {
+	ID   string `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+}
+
+// ConsumerSearch contains the text searched for in the names of the consumers
+type ConsumerSearch struct {
+	Search string `form:"search"`
+}
@@ -0,0 +1,24 @@
+package structs
+
+import "time"
+
+// Envelope wraps the data of a JSON response together with metadata about the
+// response and the links to related resources
+type Envelope struct {
+	Data  any               `json:"data"`
+	Meta  EnvelopeMeta      `json:"meta"`
+	Links map[string]string `json:"links"`
+}
+
+// EnvelopeMeta contains the metadata of an enveloped response. The page
+// settings and the total count are only set for paginated responses, the page
+// number is missing if the page has been requested using a cursor and the
+// unit is only set if the data contains usage amounts
+type EnvelopeMeta struct {
+	Page        *int                `json:"page"`
+	PageSize    *int                `json:"pageSize"`
+	Total       *int64              `json:"total"`
+	Filters     map[string][]string `json:"filters"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Unit        *string             `json:"unit"`
+}
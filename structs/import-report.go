@@ -0,0 +1,18 @@
+package structs
+
+// ImportReport summarizes the import of usage records from a file
+type ImportReport struct {
+	DryRun   bool          `json:"dryRun"`
+	Rows     int           `json:"rows"`
+	Valid    int           `json:"valid"`
+	Inserted int64         `json:"inserted"`
+	Issues   []ImportIssue `json:"issues"`
+}
+
+// ImportIssue describes an issue found in a single row of an imported file.
+// The row is counted like in a spreadsheet application, the header row being
+// the first row
+type ImportIssue struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
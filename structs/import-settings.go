@@ -0,0 +1,20 @@
+package structs
+
+// ImportSettings contains the settings used while importing usage records
+// from a CSV or XLSX file
+type ImportSettings struct {
+	DryRun     bool   `form:"dryRun,default=false"`
+	Sheet      string `form:"sheet"`
+	TimeFormat string `form:"timeFormat"`
+	ColumnMapping
+}
+
+// ColumnMapping contains the names of the columns in the header row of an
+// imported file which contain the fields of the usage records
+type ColumnMapping struct {
+	Time       string `form:"timeColumn,default=time"`
+	Amount     string `form:"amountColumn,default=amount"`
+	UsageType  string `form:"usageTypeColumn,default=usageType"`
+	ConsumerID string `form:"consumerColumn,default=consumerID"`
+	ARS        string `form:"arsColumn,default=ars"`
+}
@@ -0,0 +1,7 @@
+package structs
+
+// IngestionResult contains the number of usage records that have been stored
+// while handling a batch of usage records
+type IngestionResult struct {
+	Inserted int64 `json:"inserted"`
+}
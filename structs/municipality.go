@@ -0,0 +1,18 @@
+package structs
+
+// Municipality contains the name and metadata of a municipality from the
+// region registry
+type Municipality struct {
+	ARS        string   `json:"ars" db:"ars"`
+	Name       string   `json:"name" db:"name"`
+	Population *int64   `json:"population" db:"population"`
+	Parents    []Region `json:"parents" db:"parents"`
+}
+
+// Region contains a region from the region registry which contains a
+// municipality. The level names the regional level of the region
+type Region struct {
+	ARS   string `json:"ars"`
+	Name  string `json:"name"`
+	Level string `json:"level"`
+}
@@ -0,0 +1,14 @@
+package structs
+
+// OutputSettings contains the requested output format of a response, the
+// settings used when writing the usage records as CSV, the fields of the usage
+// records as comma separated list, the references which are expanded into the
+// usage records and if JSON responses are wrapped into an envelope
+type OutputSettings struct {
+	Format           string   `form:"format" binding:"omitempty,oneof=json csv ndjson parquet"`
+	Delimiter        string   `form:"delimiter"`
+	DecimalSeparator string   `form:"decimalSeparator"`
+	Fields           string   `form:"fields"`
+	Expand           []string `form:"expand" binding:"dive,oneof=municipality"`
+	Envelope         bool     `form:"envelope"`
+}
@@ -1,6 +1,13 @@
 package structs
 
+// PageSettings contains the pagination requested by the client. The default
+// and maximum page size are set in the configuration of the service.
+// The page size is documented as size, while older clients use pageSize.
+// Both names are accepted, but may only be combined if they are equal
 type PageSettings struct {
-	Size int `form:"pageSize,default=10000" binding:"min=1,max=100000"`
-	Page int `form:"page,default=1" binding:"min=1"`
+	Size       *int   `form:"size" binding:"omitnil,min=1"`
+	LegacySize *int   `form:"pageSize" binding:"omitnil,min=1"`
+	Page       int    `form:"page,default=1" binding:"min=1"`
+	Cursor     string `form:"cursor"`
+	Count      bool   `form:"count,default=false"`
 }
@@ -0,0 +1,12 @@
+package structs
+
+// RegionUsage contains the aggregated usage amounts of a single region
+// identified by an ARS prefix
+type RegionUsage struct {
+	ARS     string  `json:"ars" db:"ars"`
+	Sum     float64 `json:"sum" db:"sum"`
+	Average float64 `json:"average" db:"average"`
+	Minimum float64 `json:"minimum" db:"minimum"`
+	Maximum float64 `json:"maximum" db:"maximum"`
+	Count   int64   `json:"count" db:"count"`
+}
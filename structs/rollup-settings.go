@@ -0,0 +1,7 @@
+package structs
+
+// RollupSettings controls if the usages of a region are summed up per child
+// region instead of being returned as single usage records
+type RollupSettings struct {
+	Rollup bool `form:"rollup"`
+}
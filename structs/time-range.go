@@ -0,0 +1,10 @@
+package structs
+
+import "time"
+
+// TimeRange contains the optional time range a request may be restricted to.
+// The lower bound is inclusive while the upper bound is exclusive
+type TimeRange struct {
+	From  *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+}
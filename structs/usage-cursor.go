@@ -0,0 +1,21 @@
+package structs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsageCursor contains the sort key of the last usage record of a page. It is
+// used to continue the pagination after this record without using an offset.
+// Missing values are represented by their zero value as the sort order of the
+// usage records treats them the same way. The sort order the page has been
+// sorted by is stored as well, as the sort key is meaningless in another order
+type UsageCursor struct {
+	Sort       string    `json:"s"`
+	Time       time.Time `json:"t"`
+	Amount     float64   `json:"a"`
+	ConsumerID uuid.UUID `json:"c"`
+	UsageType  uuid.UUID `json:"u"`
+	ARS        string    `json:"m"`
+}
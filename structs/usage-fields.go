@@ -0,0 +1,41 @@
+package structs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// UsageFields contains the names of the fields of the usage records as used
+// in the JSON output. They are used to select and sort the fields of the usage
+// records
+var UsageFields = []string{"time", "amount", "usageType", "consumerID", "ars"}
+
+// ParseFields splits the comma separated list of fields. Every field needs to
+// be one of the UsageFields and may only be used once. The fields are returned
+// in the order of the UsageFields. If no fields are requested, nil is returned
+func ParseFields(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var requested []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(UsageFields, field) {
+			return nil, fmt.Errorf("unknown field '%s'", field)
+		}
+		if slices.Contains(requested, field) {
+			return nil, fmt.Errorf("duplicate field '%s'", field)
+		}
+		requested = append(requested, field)
+	}
+
+	var fields []string
+	for _, field := range UsageFields {
+		if slices.Contains(requested, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,11 @@
+package structs
+
+// UsageFilter contains the filter dimensions which may be combined when
+// searching for usage records. Each dimension may contain multiple values
+// which are combined using a logical OR while the dimensions themselves are
+// combined using a logical AND
+type UsageFilter struct {
+	ConsumerIDs  []string `form:"consumer"`
+	UsageTypeIDs []string `form:"usageType"`
+	ARS          []string `form:"ars"`
+}
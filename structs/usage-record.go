@@ -8,4 +8,12 @@ type UsageRecord struct {
 	UsageType  *string            `json:"usageType" db:"usage_type"`
 	ConsumerID *string            `json:"consumerID" db:"consumer"`
 	ARS        *string            `json:"ars" db:"municipality"`
+
+	// MunicipalityName is only set if the name of the municipality has been
+	// requested to be expanded into the usage record
+	MunicipalityName *string `json:"municipalityName,omitempty" db:"municipality_name"`
+
+	// Cursor is only set on the last usage record of a page if the cursor
+	// pointing to the next page has been selected
+	Cursor *UsageCursor `json:"-" db:"cursor"`
 }
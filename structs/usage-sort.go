@@ -0,0 +1,57 @@
+package structs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortSettings contains the requested sort order as comma separated list of
+// fields. Fields prefixed with a minus are sorted in descending order
+type SortSettings struct {
+	Sort string `form:"sort"`
+}
+
+// SortField contains a single field of a sort order and its direction
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// FormatSort joins the fields into a sort order as accepted by ParseSort
+func FormatSort(fields []SortField) string {
+	formattedFields := make([]string, len(fields))
+	for i, field := range fields {
+		formattedFields[i] = field.Field
+		if field.Descending {
+			formattedFields[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(formattedFields, ",")
+}
+
+// ParseSort splits the comma separated sort order into its fields. Every
+// field needs to be one of the allowed fields and may only be used once. The
+// error describes the first invalid field
+func ParseSort(value string, allowed []string) ([]SortField, error) {
+	var fields []SortField
+	if strings.TrimSpace(value) == "" {
+		return fields, nil
+	}
+
+	for _, rawField := range strings.Split(value, ",") {
+		rawField = strings.TrimSpace(rawField)
+
+		field := SortField{Field: strings.TrimPrefix(rawField, "-")}
+		field.Descending = field.Field != rawField
+
+		if !slices.Contains(allowed, field.Field) {
+			return nil, fmt.Errorf("unknown sort field '%s'", field.Field)
+		}
+		if slices.ContainsFunc(fields, func(f SortField) bool { return f.Field == field.Field }) {
+			return nil, fmt.Errorf("duplicate sort field '%s'", field.Field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
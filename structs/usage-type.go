@@ -0,0 +1,11 @@
+package structs
+
+// UsageType contains a usage type from the usage type catalogue. Usage types
+// may be categorized hierarchically by referencing a parent usage type
+type UsageType struct {
+	ID           string  `json:"id" db:"id"`
+	Name         string  `json:"name" db:"name"`
+	Description  *string `json:"description" db:"description"`
+	ExternalCode *string `json:"externalCode" db:"external_code"`
+	Parent       *string `json:"parent" db:"parent"`
+}